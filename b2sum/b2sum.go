@@ -0,0 +1,137 @@
+/*
+    go b2sum
+
+    Copyright (c) 2014-2015 Dingjun Fang
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License version 3 as
+	published by the Free Software Foundation.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+
+B2sum util implement by go.
+
+Usage: b2sum [OPTION] [FILE]...
+
+or: b2sum [OPTION] --check [FILE]
+
+Print or check BLAKE2b (512-bit) checksums.
+
+With no FILE, or when FILE is -, read standard input.
+  -c, --check   check BLAKE2 sums against given list
+
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
+      --status          don't output anything, status code shows success
+      --strict          exit non-zero for improperly formatted lines
+  -w, --warn            warn about improperly formated checksum lines
+
+      --tag       create a BSD-style checksum
+  -z, --zero      end each output line with NUL, not newline
+  -j, --jobs=N    hash N files concurrently (default: run serially)
+      --help      show help and exit
+      --version   show version and exit
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+
+	cc "github.com/ericlagergren/go-coreutils/md5sum/checksum_common"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: b2sum [OPTION] [FILE]...
+   or: b2sum [OPTION] --check [FILE]
+Print or check BLAKE2b (512-bit) checksums.
+With no FILE, or when FILE is -, read standard input.
+  -c, --check   check BLAKE2 sums against given list
+
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
+      --status          don't output anything, status code shows success
+      --strict          exit non-zero for improperly formatted lines
+  -w, --warn            warn about improperly formated checksum lines
+
+      --tag       create a BSD-style checksum
+  -z, --zero      end each output line with NUL, not newline
+  -j, --jobs=N    hash N files concurrently (default: run serially)
+      --help      show help and exit
+      --version   show version and exit
+`
+	Version = `b2sum (Go coreutils) 0.1
+Copyright (C) 2015 Dingjun Fang
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+`
+)
+
+var (
+	checkSum      = flag.BoolP("check", "c", false, "")
+	status        = flag.BoolP("status", "", false, "")
+	warn          = flag.BoolP("warn", "w", true, "")
+	tag           = flag.BoolP("tag", "", false, "")
+	zero          = flag.BoolP("zero", "z", false, "")
+	ignoreMissing = flag.BoolP("ignore-missing", "", false, "")
+	strict        = flag.BoolP("strict", "", false, "")
+	jobs          = flag.IntP("jobs", "j", 0, "")
+	showVersion   = flag.BoolP("version", "v", false, "")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Fprintf(os.Stdout, "%s", Version)
+		os.Exit(0)
+	}
+
+	cfg := cc.Config{
+		Tag:           *tag,
+		Status:        *status,
+		Warn:          *warn,
+		Zero:          *zero,
+		IgnoreMissing: *ignoreMissing,
+		Strict:        *strict,
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var ok bool
+	switch {
+	case *checkSum && *jobs > 0:
+		ok = cc.CompareChecksumParallel(files, "b2sum", cfg, *jobs)
+	case *checkSum:
+		ok = cc.CompareChecksum(files, "b2sum", cfg)
+	case *jobs > 0:
+		ok = cc.GenerateChecksumParallel(files, "b2sum", cfg, *jobs)
+	default:
+		ok = cc.GenerateChecksum(files, "b2sum", cfg)
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
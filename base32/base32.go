@@ -0,0 +1,104 @@
+/*
+	Go base32 - base32 encode or decode data.
+	Copyright (C) 2015 Robert Deusser
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Robert Deusser <iamthemuffinman@outlook.com>
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ericlagergren/go-coreutils/internal/encx"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `
+Usage: base32 [OPTION]... [FILE]
+Base32 encode or decode FILE, or standard input, to standard output.
+
+Mandatory arguments to long options are mandatory for short options too.
+  -d, --decode          decode data
+  -i, --ignore-garbage  when decoding, ignore non-alphabet characters
+  -w, --wrap=COLS       wrap encoded lines after COLS character (default 76).
+                          Use 0 to disable line wrapping
+
+      --help     display this help and exit
+      --version  output version information and exit
+
+With no FILE, or when FILE is -, read standard input.
+
+The data are encoded as described for the base32 alphabet in RFC 3548.
+When decoding, the input may contain newlines in addition to the bytes of
+the formal base32 alphabet.  Use --ignore-garbage to attempt to recover
+from any other non-alphabet bytes in the encoded stream.
+
+`
+	Version = `
+base32 (Go coreutils) 0.1
+Copyright (C) 2015 Robert Deusser
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+`
+)
+
+var (
+	decode  = flag.BoolP("decode", "d", false, "")
+	ignore  = flag.BoolP("ignore-garbage", "i", false, "")
+	wrap    = flag.IntP("wrap", "w", 76, "")
+	version = flag.BoolP("version", "v", false, "")
+)
+
+func readAndHandle(r *os.File, decode, ignore bool, wrap int) {
+	opts := encx.Options{Decode: decode, IgnoreGarbage: ignore, Wrap: wrap}
+	if err := encx.Run(os.Stdout, r, encx.Base32, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	if *version {
+		fmt.Fprintf(os.Stdout, "%s", Version)
+		os.Exit(0)
+	}
+	if *wrap < 0 {
+		log.Fatalf("invalid wrap size: %d", *wrap)
+	}
+
+	if len(flag.Args()) == 0 {
+		readAndHandle(os.Stdin, *decode, *ignore, *wrap)
+	} else {
+		for _, name := range flag.Args() {
+			file, err := os.Open(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			readAndHandle(file, *decode, *ignore, *wrap)
+			file.Close()
+		}
+	}
+}
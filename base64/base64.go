@@ -20,14 +20,11 @@
 package main
 
 import (
-	"encoding/base64"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
 	"os"
-	"unicode"
 
+	"github.com/ericlagergren/go-coreutils/internal/encx"
 	flag "github.com/ogier/pflag"
 )
 
@@ -66,81 +63,17 @@ There is NO WARRANTY, to the extent permitted by law.
 var (
 	decode  = flag.BoolP("decode", "d", false, "")
 	ignore  = flag.BoolP("ignore-garbage", "i", false, "")
-	wrap    = flag.IntP("wrap=COLS", "w", 76, "")
+	wrap    = flag.IntP("wrap", "w", 76, "")
 	version = flag.BoolP("version", "v", false, "")
 )
 
-func base64Encode(src []byte) []byte {
-	return []byte(base64.StdEncoding.EncodeToString(src))
-}
-
-func base64Decode(src []byte) ([]byte, error) {
-	return base64.StdEncoding.DecodeString(string(src))
-}
-
-func readData(reader io.Reader) ([]byte, error) {
-	return ioutil.ReadAll(reader)
-}
-
-func isAlpha(ch byte) bool {
-	return unicode.IsLetter(rune(ch))
-}
-
-func readAndHandle(reader io.Reader, decode *bool, ignore *bool, wrap *int) {
-	src, err := readData(reader)
-	checkError(err)
-	var toHandle []byte
-	if *ignore {
-		//It seems that the effect of "base64 -i" in *nix
-		//is not filter the non-alphabet charater.
-		//This flag cannot work as the original *nix command flag.
-		for i := 0; i < len(src); i++ {
-			if isAlpha(src[i]) {
-				toHandle = append(toHandle, src[i])
-			}
-		}
-	} else {
-		toHandle = src
-	}
-	if *decode {
-		decoded, err := base64Decode(toHandle)
-		checkError(err)
-		fmt.Printf("%s", string(decoded))
-	} else {
-		encoded := base64Encode(toHandle)
-		wrapPrint(encoded, *wrap)
-	}
-}
-
-func checkError(err error) {
-	if err != nil {
+func readAndHandle(r *os.File, decode, ignore bool, wrap int) {
+	opts := encx.Options{Decode: decode, IgnoreGarbage: ignore, Wrap: wrap}
+	if err := encx.Run(os.Stdout, r, encx.Base64, opts); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func wrapPrint(output []byte, wrap int) {
-	if wrap == 0 {
-		fmt.Printf("%s\n", string(output))
-		return
-	}
-
-	length := len(output)
-	if length <= wrap {
-		fmt.Printf("%s\n", string(output))
-		return
-	}
-
-	index, end := 0, 0
-	for index < length {
-		end += wrap
-		if end > length {
-			end = length
-		}
-		fmt.Printf("%s\n", string(output[index:end]))
-		index += wrap
-	}
-}
-
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s", Help)
@@ -157,12 +90,15 @@ func main() {
 	}
 
 	if len(flag.Args()) == 0 {
-		readAndHandle(os.Stdin, decode, ignore, wrap)
+		readAndHandle(os.Stdin, *decode, *ignore, *wrap)
 	} else {
-		for i := 0; i < len(flag.Args()); i++ {
-			file, err := os.Open(flag.Args()[i])
-			checkError(err)
-			readAndHandle(file, decode, ignore, wrap)
+		for _, name := range flag.Args() {
+			file, err := os.Open(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			readAndHandle(file, *decode, *ignore, *wrap)
+			file.Close()
 		}
 	}
 }
@@ -1,34 +1,13 @@
 package main
 
 import (
-	"log"
+	"bytes"
 	"testing"
-)
-
-func TestBase64Decode(t *testing.T) {
-
-	cases := []struct {
-		in, want string
-	}{
-		{"aGVsbG8gd29ybGQK", "hello world\n"},
-		{"cGxlYXNlLCBkZWNvZGUgbWUK", "please, decode me\n"},
-	}
-
-	for _, c := range cases {
-
-		decodedBytes, err := base64Decode([]byte(c.in))
-		if err != nil {
-			log.Fatal(err)
-		}
-		got := string(decodedBytes)
 
-		if got != c.want {
-			t.Errorf("base64 (%q) == %q, want %q", c.in, got, c.want)
-		}
-	}
-}
+	"github.com/ericlagergren/go-coreutils/internal/encx"
+)
 
-func TestBase64Encode(t *testing.T) {
+func TestBase64EncodeDecode(t *testing.T) {
 	cases := []struct {
 		in, want string
 	}{
@@ -37,14 +16,20 @@ func TestBase64Encode(t *testing.T) {
 	}
 
 	for _, c := range cases {
+		var enc bytes.Buffer
+		if err := encx.Run(&enc, bytes.NewBufferString(c.in), encx.Base64, encx.Options{Wrap: 76}); err != nil {
+			t.Fatalf("encode %q: %v", c.in, err)
+		}
+		if got := enc.String(); got != c.want+"\n" {
+			t.Errorf("base64 encode (%q) == %q, want %q", c.in, got, c.want+"\n")
+		}
 
-		encodedBytes := base64Encode([]byte(c.in))
-		got := string(encodedBytes)
-
-		if got != c.want {
-			t.Errorf("base64 (%q) == %q, want %q", c.in, got, c.want)
+		var dec bytes.Buffer
+		if err := encx.Run(&dec, bytes.NewBufferString(c.want), encx.Base64, encx.Options{Decode: true}); err != nil {
+			t.Fatalf("decode %q: %v", c.want, err)
+		}
+		if got := dec.String(); got != c.in {
+			t.Errorf("base64 decode (%q) == %q, want %q", c.want, got, c.in)
 		}
 	}
 }
-
-func main() {}
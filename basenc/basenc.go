@@ -0,0 +1,147 @@
+/*
+	Go basenc - encode or decode data using one of several alphabets.
+	Copyright (C) 2015 Robert Deusser
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Robert Deusser <iamthemuffinman@outlook.com>
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ericlagergren/go-coreutils/internal/encx"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `
+Usage: basenc [OPTION]... [FILE]
+Encode or decode FILE, or standard input, to standard output, using one of
+the following alphabets.
+
+  --base64          same as 'base64' program
+  --base64url       file- and url-safe base64
+  --base32          same as 'base32' program
+  --base32hex       extended hex alphabet base32
+  --base16          hex encoding
+  --z85             ascii85-like encoding
+
+Mandatory arguments to long options are mandatory for short options too.
+  -d, --decode          decode data
+  -i, --ignore-garbage  when decoding, ignore non-alphabet characters
+  -w, --wrap=COLS       wrap encoded lines after COLS character (default 76).
+                          Use 0 to disable line wrapping
+
+      --help     display this help and exit
+      --version  output version information and exit
+
+With no FILE, or when FILE is -, read standard input.
+
+`
+	Version = `
+basenc (Go coreutils) 0.1
+Copyright (C) 2015 Robert Deusser
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+`
+)
+
+var (
+	decode  = flag.BoolP("decode", "d", false, "")
+	ignore  = flag.BoolP("ignore-garbage", "i", false, "")
+	wrap    = flag.IntP("wrap", "w", 76, "")
+	version = flag.BoolP("version", "v", false, "")
+
+	base64    = flag.Bool("base64", false, "")
+	base64url = flag.Bool("base64url", false, "")
+	base32    = flag.Bool("base32", false, "")
+	base32hex = flag.Bool("base32hex", false, "")
+	base16    = flag.Bool("base16", false, "")
+	z85       = flag.Bool("z85", false, "")
+)
+
+// alphabetFlag pairs a --NAME flag with the encx.Alphabet it selects.
+type alphabetFlag struct {
+	set  *bool
+	name string
+}
+
+var alphabetFlags = []alphabetFlag{
+	{base64, "base64"},
+	{base64url, "base64url"},
+	{base32, "base32"},
+	{base32hex, "base32hex"},
+	{base16, "base16"},
+	{z85, "z85"},
+}
+
+func chosenAlphabet() encx.Alphabet {
+	var name string
+	for _, af := range alphabetFlags {
+		if *af.set {
+			if name != "" {
+				log.Fatalf("basenc: only one of --%s and --%s may be given", name, af.name)
+			}
+			name = af.name
+		}
+	}
+	if name == "" {
+		log.Fatal("basenc: exactly one alphabet flag is required")
+	}
+	return encx.Alphabets[name]
+}
+
+func readAndHandle(r *os.File, alphabet encx.Alphabet, decode, ignore bool, wrap int) {
+	opts := encx.Options{Decode: decode, IgnoreGarbage: ignore, Wrap: wrap}
+	if err := encx.Run(os.Stdout, r, alphabet, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
+	flag.Parse()
+
+	if *version {
+		fmt.Fprintf(os.Stdout, "%s", Version)
+		os.Exit(0)
+	}
+	if *wrap < 0 {
+		log.Fatalf("invalid wrap size: %d", *wrap)
+	}
+
+	alphabet := chosenAlphabet()
+
+	if len(flag.Args()) == 0 {
+		readAndHandle(os.Stdin, alphabet, *decode, *ignore, *wrap)
+	} else {
+		for _, name := range flag.Args() {
+			file, err := os.Open(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			readAndHandle(file, alphabet, *decode, *ignore, *wrap)
+			file.Close()
+		}
+	}
+}
@@ -14,11 +14,28 @@
 
 package main
 
-import "fmt"
-import flag "github.com/ogier/pflag"
-import "strconv"
-import "log"
-import "time"
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	flag "github.com/ogier/pflag"
+)
+
+var (
+	threeMonths = flag.BoolP("3", "3", false, "display the previous, current and next month side-by-side")
+	fullYear    = flag.BoolP("year", "y", false, "display the whole year")
+	mondayFirst = flag.BoolP("monday", "m", false, "weeks start on Monday instead of Sunday")
+	julian      = flag.BoolP("julian", "j", false, "print Julian day-of-year numbers instead of day-of-month")
+	noHighlight = flag.BoolP("no-highlight", "h", false, "don't reverse-video today's date")
+	locale      = flag.String("locale", "", "comma-separated list of 12 month names to use instead of the default")
+)
 
 func leapyear(year int) int {
 	//Return 1 if leapyear, 0 if not
@@ -59,51 +76,244 @@ func monthlen(month int, year int) int {
 	return 0
 }
 
-func calendar(month int, year int) {
-	weekday := int(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Weekday())
-	fmt.Printf("%s %d\n", time.Month(month).String(), year)
-	fmt.Printf("Su Mo Tu We Th Fr Sa\n")
-	for i := 0; i < weekday; i++ {
-		fmt.Printf("   ")
+var weekdayAbbr = [...]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+
+// weekdayHeader returns the "Su Mo Tu ..." row, rotated so first is the
+// leftmost column and each abbreviation padded to cellWidth so it lines
+// up with the day-number columns below it.
+func weekdayHeader(first time.Weekday, cellWidth int) string {
+	var b strings.Builder
+	for i := 0; i < 7; i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%*s", cellWidth, weekdayAbbr[(int(first)+i)%7])
+	}
+	return b.String()
+}
+
+// calendar renders a single month as a slice of rows: a "Month YYYY"
+// header, a weekday header, and one row per displayed week. firstWeekday
+// picks which column the week starts in; julian prints 1-366 day-of-year
+// numbers instead of day-of-month; today's date is reverse-videoed with
+// an ANSI escape when highlight is set.
+func calendar(month, year int, firstWeekday time.Weekday, julian, highlight bool, monthNames [12]string, today time.Time) []string {
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	lead := (int(first.Weekday()) - int(firstWeekday) + 7) % 7
+
+	cellWidth := 2
+	if julian {
+		cellWidth = 3
+	}
+
+	rows := []string{
+		fmt.Sprintf("%s %d", monthNames[month-1], year),
+		weekdayHeader(firstWeekday, cellWidth),
+	}
+
+	var row strings.Builder
+	col := 0
+	for i := 0; i < lead; i++ {
+		if col > 0 {
+			row.WriteByte(' ')
+		}
+		row.WriteString(strings.Repeat(" ", cellWidth))
+		col++
 	}
 	for day := 1; day <= monthlen(month, year); day++ {
-		if weekday == 6 {
-			fmt.Printf("%2d\n", day)
-			weekday = 0
-		} else {
-			fmt.Printf("%2d ", day)
-			weekday++
+		n := day
+		if julian {
+			n = first.AddDate(0, 0, day-1).YearDay()
+		}
+		text := fmt.Sprintf("%*d", cellWidth, n)
+		if highlight && today.Year() == year && int(today.Month()) == month && today.Day() == day {
+			text = "\x1b[7m" + text + "\x1b[0m"
+		}
+		if col > 0 {
+			row.WriteByte(' ')
+		}
+		row.WriteString(text)
+		col++
+		if col == 7 {
+			rows = append(rows, row.String())
+			row.Reset()
+			col = 0
+		}
+	}
+	if col != 0 {
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// visibleWidth is len(s) with any ANSI highlight escapes discounted, so
+// joinRows can pad columns by what a terminal actually displays.
+func visibleWidth(s string) int {
+	return len(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// joinRows zips the rows of several months side-by-side, separated by
+// gap, padding every column to its own widest row and padding out
+// shorter month's row count with blank rows so every column lines up.
+func joinRows(cols [][]string, gap string) []string {
+	maxRows, widths := 0, make([]int, len(cols))
+	for i, c := range cols {
+		if len(c) > maxRows {
+			maxRows = len(c)
+		}
+		for _, r := range c {
+			if w := visibleWidth(r); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	out := make([]string, maxRows)
+	for r := 0; r < maxRows; r++ {
+		var line strings.Builder
+		for i, c := range cols {
+			if i > 0 {
+				line.WriteString(gap)
+			}
+			var cell string
+			if r < len(c) {
+				cell = c[r]
+			}
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)))
+		}
+		out[r] = strings.TrimRight(line.String(), " ")
+	}
+	return out
+}
+
+// threeMonthRows lays the month before, the month of, and the month
+// after out side-by-side, the way ncal -3 does.
+func threeMonthRows(month, year int, firstWeekday time.Weekday, julian, highlight bool, monthNames [12]string, today time.Time) []string {
+	prevMonth, prevYear := month-1, year
+	if prevMonth < 1 {
+		prevMonth, prevYear = 12, year-1
+	}
+	nextMonth, nextYear := month+1, year
+	if nextMonth > 12 {
+		nextMonth, nextYear = 1, year+1
+	}
+
+	cols := [][]string{
+		calendar(prevMonth, prevYear, firstWeekday, julian, highlight, monthNames, today),
+		calendar(month, year, firstWeekday, julian, highlight, monthNames, today),
+		calendar(nextMonth, nextYear, firstWeekday, julian, highlight, monthNames, today),
+	}
+	return joinRows(cols, "  ")
+}
+
+// yearRows lays out all twelve months of year in a 3-column, 4-row
+// grid, the way ncal -y does.
+func yearRows(year int, firstWeekday time.Weekday, julian, highlight bool, monthNames [12]string, today time.Time) []string {
+	var out []string
+	for group := 0; group < 4; group++ {
+		var cols [][]string
+		for col := 0; col < 3; col++ {
+			month := group*3 + col + 1
+			cols = append(cols, calendar(month, year, firstWeekday, julian, highlight, monthNames, today))
 		}
+		if group > 0 {
+			out = append(out, "")
+		}
+		out = append(out, joinRows(cols, "  ")...)
+	}
+	return out
+}
+
+// parseLocale splits a --locale=Jan,Feb,...,Dec argument into the 12
+// month names calendar should print instead of time.Month.String()'s
+// defaults.
+func parseLocale(s string) ([12]string, error) {
+	var names [12]string
+	parts := strings.Split(s, ",")
+	if len(parts) != 12 {
+		return names, fmt.Errorf("--locale needs exactly 12 comma-separated month names, got %d", len(parts))
 	}
-	if weekday != 6 {
-		fmt.Printf("\n")
+	copy(names[:], parts)
+	return names, nil
+}
+
+func defaultMonthNames() [12]string {
+	var names [12]string
+	for i := range names {
+		names[i] = time.Month(i + 1).String()
 	}
+	return names
+}
+
+// isTerminal reports whether fd refers to a terminal, so the default
+// today-highlight can be skipped when stdout is redirected to a file or
+// pipe.
+func isTerminal(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+	return err == nil
 }
 
 func main() {
 	flag.Parse()
-	if len(flag.Args()) == 0 {
-		year := int(time.Now().Year())
-		month := int(time.Now().Month())
-		calendar(month, year)
-	} else if len(flag.Args()) == 1 {
-		year, err := strconv.Atoi(flag.Arg(0))
+
+	monthNames := defaultMonthNames()
+	if *locale != "" {
+		names, err := parseLocale(*locale)
 		if err != nil {
 			log.Fatal(err)
 		}
-		for month := 1; month <= 12; month++ {
-			calendar(month, year)
-			fmt.Println()
+		monthNames = names
+	}
+
+	firstWeekday := time.Sunday
+	if *mondayFirst {
+		firstWeekday = time.Monday
+	}
+
+	highlight := !*noHighlight && isTerminal(os.Stdout.Fd())
+	today := time.Now()
+
+	year, month := today.Year(), int(today.Month())
+	yearOnly := false
+
+	switch len(flag.Args()) {
+	case 0:
+		// Use today's month/year.
+	case 1:
+		y, err := strconv.Atoi(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
 		}
-	} else if len(flag.Args()) == 2 {
-		month, err := strconv.Atoi(flag.Arg(0))
+		year = y
+		yearOnly = true
+	case 2:
+		m, err := strconv.Atoi(flag.Arg(0))
 		if err != nil {
 			log.Fatal(err)
 		}
-		year, err := strconv.Atoi(flag.Arg(1))
+		y, err := strconv.Atoi(flag.Arg(1))
 		if err != nil {
 			log.Fatal(err)
 		}
-		calendar(month, year)
+		month, year = m, y
+	default:
+		log.Fatalf("extra operand %s", flag.Arg(2))
+	}
+
+	var rows []string
+	switch {
+	case *fullYear || yearOnly:
+		rows = yearRows(year, firstWeekday, *julian, highlight, monthNames, today)
+	case *threeMonths:
+		rows = threeMonthRows(month, year, firstWeekday, *julian, highlight, monthNames, today)
+	default:
+		rows = calendar(month, year, firstWeekday, *julian, highlight, monthNames, today)
+	}
+
+	for _, row := range rows {
+		fmt.Println(row)
 	}
 }
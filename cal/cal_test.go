@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarSundayStart(t *testing.T) {
+	today := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	got := strings.Join(calendar(7, 2026, time.Sunday, false, false, defaultMonthNames(), today), "\n")
+	want := `July 2026
+Su Mo Tu We Th Fr Sa
+          1  2  3  4
+ 5  6  7  8  9 10 11
+12 13 14 15 16 17 18
+19 20 21 22 23 24 25
+26 27 28 29 30 31`
+
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCalendarMondayStartJulian(t *testing.T) {
+	today := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	got := strings.Join(calendar(7, 2026, time.Monday, true, false, defaultMonthNames(), today), "\n")
+	want := ` Mo  Tu  We  Th  Fr  Sa  Su
+        182 183 184 185 186
+187 188 189 190 191 192 193
+194 195 196 197 198 199 200
+201 202 203 204 205 206 207
+208 209 210 211 212`
+
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("got:\n%s\nwant suffix:\n%s", got, want)
+	}
+}
+
+func TestCalendarHighlightsToday(t *testing.T) {
+	today := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	rows := calendar(7, 2026, time.Sunday, false, true, defaultMonthNames(), today)
+
+	var found bool
+	for _, r := range rows {
+		if strings.Contains(r, "\x1b[7m27\x1b[0m") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("today (27) was not highlighted in any row: %v", rows)
+	}
+
+	// A highlight-free render of the same month must be byte-identical
+	// once the escapes are stripped.
+	plain := calendar(7, 2026, time.Sunday, false, false, defaultMonthNames(), today)
+	for i, r := range rows {
+		if visibleWidth(r) != len(plain[i]) {
+			t.Errorf("row %d: highlighted visible width %d != plain width %d", i, visibleWidth(r), len(plain[i]))
+		}
+	}
+}
+
+func TestThreeMonthRowsWrapsYearBoundary(t *testing.T) {
+	today := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := threeMonthRows(1, 2026, time.Sunday, false, false, defaultMonthNames(), today)
+	if !strings.Contains(rows[0], "December 2025") {
+		t.Errorf("first header row = %q, want it to mention December 2025", rows[0])
+	}
+	if !strings.Contains(rows[0], "January 2026") {
+		t.Errorf("first header row = %q, want it to mention January 2026", rows[0])
+	}
+	if !strings.Contains(rows[0], "February 2026") {
+		t.Errorf("first header row = %q, want it to mention February 2026", rows[0])
+	}
+}
+
+func TestYearRowsHasTwelveMonths(t *testing.T) {
+	today := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+	rows := yearRows(2026, time.Sunday, false, false, defaultMonthNames(), today)
+
+	joined := strings.Join(rows, "\n")
+	for _, name := range defaultMonthNames() {
+		if !strings.Contains(joined, name+" 2026") {
+			t.Errorf("year grid missing %s 2026", name)
+		}
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	names, err := parseLocale("Jan,Feb,Mar,Apr,May,Jun,Jul,Aug,Sep,Oct,Nov,Dec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names[0] != "Jan" || names[11] != "Dec" {
+		t.Errorf("got %v", names)
+	}
+
+	if _, err := parseLocale("Jan,Feb"); err == nil {
+		t.Error("expected an error for a locale with fewer than 12 names")
+	}
+}
@@ -1,36 +1,47 @@
 // Copyright (c) 2014-2016 Eric Lagergren
 // Use of this source code is governed by the GPL v3 or later.
 
-package main
+package cat
 
 import (
 	"bufio"
-	"fmt"
+	"errors"
 	"io"
-	"log"
 	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/EricLagergren/go-coreutils/internal/flag"
+	coreutils "github.com/ericlagergren/go-coreutils"
+	flag "github.com/spf13/pflag"
 )
 
-var (
-	all        = flag.BoolP("show-all", "A", false, "equivalent to -vET")
-	blank      = flag.BoolP("number-nonblank", "b", false, "number nonempty output lines, overrides -n")
-	npEnds     = flag.BoolP("ends", "e", false, "equivalent to -vE")
-	ends       = flag.BoolP("show-ends", "E", false, "display $ at end of each line")
-	number     = flag.BoolP("number", "n", false, "number all output lines")
-	squeeze    = flag.BoolP("squeeze-blank", "s", false, "suppress repeated empty output lines")
-	npTabs     = flag.BoolP("tabs", "t", false, "equivalent to -vT")
-	tabs       = flag.BoolP("show-tabs", "T", false, "display TAB characters as ^I")
-	nonPrint   = flag.BoolP("non-printing", "v", false, "use ^ and M- notation, except for LFD and TAB")
-	unbuffered = flag.BoolP("unbuffered", "u", false, "(ignored)")
+func init() {
+	coreutils.Register("cat", run)
+}
 
-	totalNewline    int64
-	showNonPrinting bool
-	simple          bool
+// run adapts Run's GNU-style exit code to the error Runnable expects.
+func run(ctx coreutils.Context, args ...string) error {
+	if Run(ctx, args...) != 0 {
+		return errors.New("cat: exit status 1")
+	}
+	return nil
+}
 
-	fatal = log.New(os.Stderr, "", 0)
-)
+// sameFileAsOutput reports whether in, already stat'd as inInfo, is the
+// same regular file outInfo refers to (outReg is outInfo.Mode().IsRegular(),
+// computed once by the caller) and is positioned before its current end,
+// i.e. this is a genuine "cat file > file" clobber rather than the
+// safe "cat file >> file" append. It's portable -- os.SameFile works on
+// every platform Go supports -- so both cat_unix.go and cat_windows.go
+// call through this one implementation rather than keeping their own.
+func sameFileAsOutput(outInfo os.FileInfo, outReg bool, in *os.File, inInfo os.FileInfo) bool {
+	if !outReg || !os.SameFile(outInfo, inInfo) {
+		return false
+	}
+	off, err := in.Seek(0, io.SeekCurrent)
+	return err == nil && off < inInfo.Size()
+}
 
 const caret = '^'
 
@@ -45,16 +56,91 @@ const (
 	lineEnd = lineLen - 2
 )
 
-var (
-	lineBuf = [...]byte{
-		' ', ' ', ' ', ' ', ' ',
-		' ', ' ', ' ', ' ', ' ',
-		' ', ' ', ' ', ' ', ' ',
-		' ', ' ', ' ', '0', '\t',
+// writeNonPrintingByte renders a single byte using the classic cat -v
+// M-/^ notation: ch itself if it's printable ASCII, ^X for C0 controls
+// and DEL, and M- (optionally followed by ^X) for anything >= 128. It's
+// the fallback cat() reaches for whenever showNonPrinting can't display
+// something more meaningfully -- plain non-UTF8 bytes, or, in --utf8
+// mode, bytes that turned out not to be part of a valid rune.
+func writeNonPrintingByte(w *bufio.Writer, ch byte) {
+	switch {
+	case ch < 32:
+		w.WriteByte(caret)
+		w.WriteByte(ch + 64)
+	case ch < 127:
+		w.WriteByte(ch)
+	case ch == 127:
+		w.Write(delete_)
+	case ch-128 == 127:
+		w.Write(emdash)
+		w.Write(delete_)
+	case ch-128 < 32:
+		w.Write(emdash)
+		w.WriteByte(caret)
+		w.WriteByte(ch - 128 + 64)
+	default:
+		w.Write(emdash)
+		w.WriteByte(ch - 128)
 	}
-	linePrint = lineLen - 7
-	lineStart = lineLen - 2
-)
+}
+
+// localeIsUTF8 reports whether LC_ALL or LC_CTYPE (LC_ALL taking
+// precedence, same as libc) names a UTF-8 locale, e.g. en_US.UTF-8 or
+// C.UTF-8. It picks --utf8's default so --show-nonprinting doesn't
+// mangle ordinary UTF-8 text out of the box on the locales most systems
+// actually run with.
+func localeIsUTF8() bool {
+	loc := os.Getenv("LC_ALL")
+	if loc == "" {
+		loc = os.Getenv("LC_CTYPE")
+	}
+	loc = strings.ToLower(loc)
+	return strings.Contains(loc, "utf-8") || strings.Contains(loc, "utf8")
+}
+
+// cmd holds the flags and the state a single cat invocation accumulates
+// while it walks its file operands. Keeping totalNewline, lineBuf, and
+// friends here instead of on package-level globals lets concurrent Run
+// calls (e.g. from tests, or a future multi-call binary) coexist.
+type cmd struct {
+	f flag.FlagSet
+
+	all, blank, npEnds, ends, number, squeeze, npTabs, tabs, nonPrint, unbuffered bool
+
+	showNonPrinting bool
+	utf8            bool
+	simple          bool
+	totalNewline    int64
+
+	lineBuf   [lineLen]byte
+	linePrint int
+	lineStart int
+}
+
+func newCommand() *cmd {
+	c := &cmd{
+		lineBuf: [lineLen]byte{
+			' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', ' ', ' ',
+			' ', ' ', ' ', '0', '\t',
+		},
+		linePrint: lineLen - 7,
+		lineStart: lineLen - 2,
+	}
+	c.f.BoolVarP(&c.all, "show-all", "A", false, "equivalent to -vET")
+	c.f.BoolVarP(&c.blank, "number-nonblank", "b", false, "number nonempty output lines, overrides -n")
+	c.f.BoolVarP(&c.npEnds, "ends", "e", false, "equivalent to -vE")
+	c.f.BoolVarP(&c.ends, "show-ends", "E", false, "display $ at end of each line")
+	c.f.BoolVarP(&c.number, "number", "n", false, "number all output lines")
+	c.f.BoolVarP(&c.squeeze, "squeeze-blank", "s", false, "suppress repeated empty output lines")
+	c.f.BoolVarP(&c.npTabs, "tabs", "t", false, "equivalent to -vT")
+	c.f.BoolVarP(&c.tabs, "show-tabs", "T", false, "display TAB characters as ^I")
+	c.f.BoolVarP(&c.nonPrint, "non-printing", "v", false, "use ^ and M- notation, except for LFD and TAB")
+	c.f.BoolVarP(&c.utf8, "utf8", "U", localeIsUTF8(), "with -v, decode UTF-8 runes and print them verbatim instead of mangling them byte-by-byte")
+	c.f.BoolVarP(&c.unbuffered, "unbuffered", "u", false, "(ignored)")
+	return c
+}
 
 func max(a, b int) int {
 	if a > b {
@@ -63,53 +149,83 @@ func max(a, b int) int {
 	return b
 }
 
-func nextLineNum() {
+func (c *cmd) nextLineNum() {
 	ep := lineEnd
 	for {
 		// if it's possible, increment the line number
-		if lineBuf[ep] < '9' {
-			lineBuf[ep]++
+		if c.lineBuf[ep] < '9' {
+			c.lineBuf[ep]++
 			return
 		}
 
 		// otherwise, set it to 0 and move backwards
-		lineBuf[ep] = '0'
+		c.lineBuf[ep] = '0'
 		ep--
 
 		// stop when we've moved past our printing area
-		if ep < lineStart {
+		if ep < c.lineStart {
 			break
 		}
 	}
 
 	// who needs pointer arithmetic? ...said nobody ever
-	if lineStart < len(lineBuf) {
-		lineStart--
-		lineBuf[lineStart] = '1'
+	if c.lineStart < len(c.lineBuf) {
+		c.lineStart--
+		c.lineBuf[c.lineStart] = '1'
 	} else {
-		lineBuf[0] = '>'
+		c.lineBuf[0] = '>'
 	}
 
-	if lineStart < linePrint {
-		linePrint--
+	if c.lineStart < c.linePrint {
+		c.linePrint--
 	}
 }
 
-// simple cat, meaning no formatting -- just copy from input to stdout
-func simpleCat(r io.Reader, w io.Writer) int {
-	_, err := io.Copy(w, r)
-	if err != nil {
-		fatal.Fatalln(err)
+// simpleCat copies r to w with no formatting at all. When inFile and
+// outFile are both non-nil (i.e. r and w are the *os.File underneath
+// them), it first tries fastCopy, which asks the kernel to move the
+// bytes directly via copy_file_range/sendfile/splice instead of
+// bouncing them through a userspace buffer. w must already wrap
+// outFile, if outFile is non-nil, so flushing it here empties anything
+// buffered before the fast path starts writing straight to the fd.
+func (c *cmd) simpleCat(r io.Reader, w *bufio.Writer, inFile, outFile *os.File) int {
+	if inFile != nil && outFile != nil {
+		if err := w.Flush(); err != nil {
+			return 1
+		}
+		if _, err, ok := fastCopy(outFile, inFile); ok {
+			if err != nil {
+				return 1
+			}
+			return 0
+		}
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return 1
 	}
 	return 0 // success! :-)
 }
 
-func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
-	newlines := totalNewline // total newlines across invocations
-	var eob int              // end of buffer
-	bpin := eob + 1          // beginning of buffer
-	var ch byte              // char in buffer
-	size := len(buf) - 1     // len of buffer with room for sentinel byte
+func (c *cmd) cat(r io.Reader, buf []byte, w *bufio.Writer) int {
+	newlines := c.totalNewline // total newlines across invocations
+	var eob int                // end of buffer
+	bpin := eob + 1            // beginning of buffer
+	var ch byte                // char in buffer
+	size := len(buf) - 1       // len of buffer with room for sentinel byte
+
+	// carry holds the trailing bytes of a UTF-8 sequence that --utf8
+	// mode found was still incomplete when it ran into eob; the next
+	// refill prepends them so DecodeRune sees the whole thing instead
+	// of misreporting a split rune as an invalid byte. Only --utf8
+	// mode ever populates it.
+	var carry [utf8.UTFMax - 1]byte
+	var carryLen int
+
+	// midLineRefill is set when a refill was forced by one of those
+	// split runes rather than by an actual line ending, so the
+	// "beginning of a line" bookkeeping below knows to stay out of the
+	// way -- we're resuming the same line, not starting a new one.
+	var midLineRefill bool
 
 	// When I first tried translating this from C the algorithm
 	// Torbjorn and rms used sort of confused me, so I'll try to explain
@@ -145,21 +261,32 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 			// of our buffer). If that's the case, read() some more and
 			// continue our loops.
 			if bpin > eob {
-				n, err := r.Read(buf[:size])
+				if carryLen > 0 {
+					copy(buf, carry[:carryLen])
+				}
+				n, err := r.Read(buf[carryLen:size])
 				if err == io.EOF {
-					totalNewline = newlines
+					// A split rune that never got completed because
+					// there's simply no more input left -- it was
+					// invalid all along, so flush it byte-by-byte
+					// instead of silently dropping it.
+					for _, b := range carry[:carryLen] {
+						writeNonPrintingByte(w, b)
+					}
+					c.totalNewline = newlines
 					w.Flush()
 					return 0
 				}
 				if err != nil {
-					totalNewline = newlines
+					c.totalNewline = newlines
 					w.Flush()
 					return 1
 				}
 
-				bpin = 0      // Reset bpin to the beginning of the buffer
-				eob = n       // End of buffer is the number of bytes read
-				buf[eob] = 10 // Place a sentinel at the end of the buffer
+				bpin = 0           // Reset bpin to the beginning of the buffer
+				eob = carryLen + n // End of buffer includes any carried-over bytes
+				carryLen = 0       // they've been consumed into buf now
+				buf[eob] = 10      // Place a sentinel at the end of the buffer
 			} else {
 
 				// If we don't have to read anything, we check to see if
@@ -171,7 +298,7 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 						newlines = 2
 
 						// Multiple blank lines?
-						if *squeeze {
+						if c.squeeze {
 							ch = buf[bpin]
 							bpin++
 
@@ -181,14 +308,14 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 					}
 
 					// Line numbers for *empty* lines
-					if *number && !*blank {
-						nextLineNum()
-						w.Write(lineBuf[linePrint:])
+					if c.number && !c.blank {
+						c.nextLineNum()
+						w.Write(c.lineBuf[c.linePrint:])
 					}
 				}
 
 				// Add '$' at EOL if requested
-				if *ends {
+				if c.ends {
 					w.WriteByte('$')
 				}
 
@@ -212,11 +339,14 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 			}
 		}
 
-		// Beginning of a line with line numbers requested?
-		if newlines >= 0 && *number {
-			nextLineNum()
-			w.Write(lineBuf[linePrint:])
+		// Beginning of a line with line numbers requested? Skip this if
+		// we only got here because a split rune forced a refill
+		// mid-line -- that's not a new line, just more of the same one.
+		if newlines >= 0 && c.number && !midLineRefill {
+			c.nextLineNum()
+			w.Write(c.lineBuf[c.linePrint:])
 		}
+		midLineRefill = false
 
 		// At this point ch will not be a newline, so we loop over
 		// the entire buffer until we find a newline. If we find a newline,
@@ -224,7 +354,68 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 		// than eob because our buffer is (usually) 4096 bytes, and
 		// newlines (usually) occur more often than once per 4096 bytes.
 
-		if showNonPrinting {
+		if c.showNonPrinting && c.utf8 {
+			for {
+				if ch == 10 {
+					newlines = -1
+					break
+				}
+
+				if ch < utf8.RuneSelf {
+					switch {
+					case ch == 9 && !c.tabs:
+						w.WriteByte(9)
+					case ch >= 32 && ch < 127:
+						w.WriteByte(ch)
+					case ch == 127:
+						w.Write(delete_)
+					default:
+						w.WriteByte(caret)
+						w.WriteByte(ch + 64)
+					}
+					ch = buf[bpin]
+					bpin++
+					continue
+				}
+
+				// ch >= 0x80: either the lead byte of a multi-byte
+				// rune or a byte that isn't valid UTF-8 on its own.
+				start := bpin - 1
+				data := buf[start:eob]
+				if !utf8.FullRune(data) {
+					// Not invalid -- just short, because it's sitting
+					// at the end of what this Read returned. Carry it
+					// into the next refill instead of mangling it.
+					carryLen = copy(carry[:], data)
+					bpin = eob + 1
+					midLineRefill = true
+					break
+				}
+
+				rn, size := utf8.DecodeRune(data)
+				switch {
+				case rn == utf8.RuneError && size == 1:
+					writeNonPrintingByte(w, ch)
+				case rn >= 0x80 && rn <= 0x9f:
+					// C1 control, rendered the same way -v renders
+					// its C0 cousins: M- for the high bit, ^X for
+					// the control code itself.
+					w.Write(emdash)
+					w.WriteByte(caret)
+					w.WriteByte(byte(rn-0x80) + 64)
+				case unicode.IsPrint(rn):
+					w.WriteRune(rn)
+				default:
+					for _, b := range data[:size] {
+						writeNonPrintingByte(w, b)
+					}
+				}
+
+				bpin = start + size
+				ch = buf[bpin]
+				bpin++
+			}
+		} else if c.showNonPrinting {
 			for {
 				if ch >= 32 {
 					if ch < 127 {
@@ -244,7 +435,7 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 							w.WriteByte(ch - 128 + 64)
 						}
 					}
-				} else if ch == 9 && !*tabs {
+				} else if ch == 9 && !c.tabs {
 					w.WriteByte(9)
 				} else if ch == 10 {
 					newlines = -1
@@ -262,7 +453,7 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 		} else {
 			// Not non-printing
 			for {
-				if ch == 9 && *tabs {
+				if ch == 9 && c.tabs {
 					w.Write(horizTab)
 				} else if ch != 10 {
 					w.WriteByte(ch)
@@ -278,38 +469,3 @@ func cat(r io.Reader, buf []byte, w *bufio.Writer) int {
 		}
 	}
 }
-
-func init() {
-	flag.Usage = func() {
-		fmt.Printf(`Usage: %s [OPTION]... [FILE]...
-Concatenate FILE(s), or standard input, to standard output.
-
-`, flag.Program)
-		flag.DBE()
-	}
-	flag.ProgVersion = "2.0"
-	flag.Parse()
-
-	// -vET
-	if *all {
-		*nonPrint = true
-		*npTabs = true
-		*npEnds = true
-	}
-	if *npEnds {
-		*ends = true
-	}
-	if *blank {
-		*number = true
-	}
-	if *npTabs {
-		*tabs = true
-	}
-	if *all || *npEnds || *npTabs || *nonPrint {
-		showNonPrinting = true
-	}
-	if !(*number || *ends || showNonPrinting ||
-		*tabs || *squeeze) {
-		simple = true
-	}
-}
@@ -0,0 +1,141 @@
+// Copyright (c) 2014-2016 Eric Lagergren
+// Use of this source code is governed by the GPL v3 or later.
+
+package cat
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+type fdKind int
+
+const (
+	kindOther fdKind = iota
+	kindRegular
+	kindPipe
+	kindSocket
+)
+
+func classify(fi os.FileInfo) fdKind {
+	switch {
+	case fi.Mode().IsRegular():
+		return kindRegular
+	case fi.Mode()&os.ModeNamedPipe != 0:
+		return kindPipe
+	case fi.Mode()&os.ModeSocket != 0:
+		return kindSocket
+	default:
+		return kindOther
+	}
+}
+
+func sameFilesystem(a, b os.FileInfo) bool {
+	as, aok := a.Sys().(*syscall.Stat_t)
+	bs, bok := b.Sys().(*syscall.Stat_t)
+	return aok && bok && as.Dev == bs.Dev
+}
+
+// fastCopy moves src's remaining bytes into dst using the kernel's
+// zero-copy primitives where one applies. It reports ok=false when no
+// primitive applies to this pair of endpoints, and also when the one
+// that applies fails (err then holds that failure); either way the
+// caller should then fall back to a plain, buffered io.Copy.
+//
+//   - regular file  -> regular file, same filesystem: copy_file_range(2)
+//   - regular file  -> socket:                        sendfile(2)
+//   - pipe involved on either side:                    splice(2)
+func fastCopy(dst, src *os.File) (n int64, err error, ok bool) {
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return 0, nil, false
+	}
+	dstInfo, err := dst.Stat()
+	if err != nil {
+		return 0, nil, false
+	}
+
+	srcKind := classify(srcInfo)
+	dstKind := classify(dstInfo)
+
+	switch {
+	case srcKind == kindRegular && dstKind == kindRegular && sameFilesystem(srcInfo, dstInfo):
+		n, err = copyFileRange(dst, src, srcInfo.Size())
+		return n, err, err == nil
+	case srcKind == kindRegular && dstKind == kindSocket:
+		n, err = sendFile(dst, src, srcInfo.Size())
+		return n, err, err == nil
+	case srcKind == kindPipe || dstKind == kindPipe:
+		n, err = splice(dst, src)
+		return n, err, err == nil
+	default:
+		return 0, nil, false
+	}
+}
+
+func copyFileRange(dst, src *os.File, size int64) (int64, error) {
+	var total int64
+	for total < size {
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, int(size-total), 0)
+		if n > 0 {
+			total += int64(n)
+		}
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return total, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
+func sendFile(dst, src *os.File, size int64) (int64, error) {
+	var total int64
+	for total < size {
+		n, err := unix.Sendfile(int(dst.Fd()), int(src.Fd()), nil, int(size-total))
+		if n > 0 {
+			total += int64(n)
+		}
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return total, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
+// splicePipeBuf is the amount spliced per syscall; splice(2) has no
+// notion of "copy until EOF" like sendfile does for regular files, so
+// this loops until it sees a zero-length result.
+const splicePipeBuf = 1 << 20
+
+func splice(dst, src *os.File) (int64, error) {
+	var total int64
+	for {
+		n, err := unix.Splice(int(src.Fd()), nil, int(dst.Fd()), nil, splicePipeBuf, 0)
+		if n > 0 {
+			total += n
+		}
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return total, err
+		}
+		if n == 0 {
+			// The source is exhausted -- a normal, successful finish.
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// +build linux
+
+// Copyright (c) 2014-2016 Eric Lagergren
+// Use of this source code is governed by the GPL v3 or later.
+
+package cat
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchSize is 1 GiB, the size the zero-copy fast path is meant to pay
+// off on; run these with -benchtime=3x or so, since Go's default
+// adaptive iteration count will otherwise copy several GiB per line.
+const benchSize = 1 << 30
+
+func makeBenchFile(b *testing.B, dir, name string) *os.File {
+	b.Helper()
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := f.Truncate(benchSize); err != nil {
+		b.Fatal(err)
+	}
+	f.Close()
+
+	f, err = os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { f.Close() })
+	return f
+}
+
+func openBenchDst(b *testing.B, dir, name string) *os.File {
+	b.Helper()
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { f.Close() })
+	return f
+}
+
+func BenchmarkSimpleCatIOCopyRegular(b *testing.B) {
+	dir := b.TempDir()
+	c := newCommand()
+	b.SetBytes(benchSize)
+
+	for i := 0; i < b.N; i++ {
+		src := makeBenchFile(b, dir, "src")
+		dst := openBenchDst(b, dir, "dst")
+		w := bufio.NewWriterSize(dst, 128*1024)
+
+		// Force the plain io.Copy path by not passing the *os.File pair
+		// through, the same way simpleCat behaves when either side
+		// isn't a real file.
+		if c.simpleCat(src, w, nil, nil) != 0 {
+			b.Fatal("copy failed")
+		}
+		src.Close()
+		dst.Close()
+	}
+}
+
+func BenchmarkSimpleCatFastCopyRegular(b *testing.B) {
+	dir := b.TempDir()
+	c := newCommand()
+	b.SetBytes(benchSize)
+
+	for i := 0; i < b.N; i++ {
+		src := makeBenchFile(b, dir, "src")
+		dst := openBenchDst(b, dir, "dst")
+		w := bufio.NewWriterSize(dst, 128*1024)
+
+		if c.simpleCat(src, w, src, dst) != 0 {
+			b.Fatal("copy failed")
+		}
+		src.Close()
+		dst.Close()
+	}
+}
+
+func BenchmarkSimpleCatIOCopyPipe(b *testing.B) {
+	c := newCommand()
+	b.SetBytes(benchSize)
+
+	for i := 0; i < b.N; i++ {
+		r, w := mustPipe(b)
+		dir := b.TempDir()
+		dst := openBenchDst(b, dir, "dst")
+		out := bufio.NewWriterSize(dst, 128*1024)
+
+		go feedPipe(b, w, benchSize)
+
+		if c.simpleCat(r, out, nil, nil) != 0 {
+			b.Fatal("copy failed")
+		}
+		r.Close()
+		dst.Close()
+	}
+}
+
+func BenchmarkSimpleCatFastCopyPipe(b *testing.B) {
+	c := newCommand()
+	b.SetBytes(benchSize)
+
+	for i := 0; i < b.N; i++ {
+		r, w := mustPipe(b)
+		dir := b.TempDir()
+		dst := openBenchDst(b, dir, "dst")
+		out := bufio.NewWriterSize(dst, 128*1024)
+
+		go feedPipe(b, w, benchSize)
+
+		if c.simpleCat(r, out, r, dst) != 0 {
+			b.Fatal("copy failed")
+		}
+		r.Close()
+		dst.Close()
+	}
+}
+
+func mustPipe(b *testing.B) (*os.File, *os.File) {
+	b.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		b.Fatal(err)
+	}
+	return r, w
+}
+
+func feedPipe(b *testing.B, w *os.File, size int64) {
+	defer w.Close()
+	if _, err := io.CopyN(w, zeroReader{}, size); err != nil {
+		b.Error(err)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+// Copyright (c) 2014-2016 Eric Lagergren
+// Use of this source code is governed by the GPL v3 or later.
+
+package cat
+
+import "os"
+
+// fastCopy has no zero-copy primitive to offer outside Linux
+// (copy_file_range/sendfile/splice are Linux syscalls), so it always
+// defers to the caller's plain, buffered io.Copy.
+func fastCopy(dst, src *os.File) (n int64, err error, ok bool) {
+	return 0, nil, false
+}
@@ -1,6 +1,6 @@
 // +build linux
 
-package main
+package cat
 
 import (
 	"bufio"
@@ -23,10 +23,13 @@ var buf bytes.Buffer
 
 func TestCat(t *testing.T) {
 
-	showNonPrinting = true
-	*nonPrint = true
-	*npEnds = true
-	*npTabs = true
+	c := newCommand()
+	c.showNonPrinting = true
+	c.nonPrint = true
+	c.npEnds = true
+	c.npTabs = true
+	c.ends = true
+	c.tabs = true
 
 	for i, f := range flist {
 
@@ -56,7 +59,7 @@ func TestCat(t *testing.T) {
 		outBuf := bufio.NewWriterSize(os.Stdout, size)
 		inBuf := make([]byte, inBsize+1)
 
-		cat(file, inBuf, outBuf)
+		c.cat(file, inBuf, outBuf)
 		file.Close()
 
 		// capture the stdout
@@ -1,22 +1,27 @@
 // Copyright (c) 2014-2016 Eric Lagergren
 // Use of this source code is governed by the GPL v3 or later.
 
-package main
+package cat
 
 import (
 	"bufio"
-	"log"
+	"fmt"
 	"os"
 
-	"github.com/EricLagergren/go-coreutils/internal/flag"
+	coreutils "github.com/ericlagergren/go-coreutils"
 
 	"golang.org/x/sys/unix"
 )
 
+// defaultBsize is used in place of a real blksize when the stream isn't
+// backed by an *os.File (e.g. a ctx.Stdin/ctx.Stdout supplied by a
+// caller other than the dispatcher's os.Stdin/os.Stdout).
+const defaultBsize = 128 * 1024
+
 func bsize(info os.FileInfo) int {
 	stat, ok := info.Sys().(*unix.Stat_t)
 	if !ok {
-		log.Fatalln("cat: (bug) inf.Sys().(*unix.Stat_t) is false")
+		return defaultBsize
 	}
 	// (Taken from ioblksize.h)
 	// bufSize is determined by:
@@ -28,67 +33,116 @@ func bsize(info os.FileInfo) int {
 	//       dd bs=$bs if=/dev/zero of=/dev/null 2>&1 \
 	//         | sed -n 's/.* \([0-9.]* [GM]B\/s\)/\1/p'
 	// done
-	const bufSize = 128 * 1024
-	return max(bufSize, int(stat.Blksize))
+	return max(defaultBsize, int(stat.Blksize))
 }
 
-func main() {
-	var ok int // return status
+// Run concatenates the files named in args (standard input, if args is
+// empty or names "-") onto ctx.Stdout, the way the cat utility does, and
+// returns the exit status the caller should use.
+func Run(ctx coreutils.Context, args ...string) int {
+	c := newCommand()
+	if err := c.f.Parse(args); err != nil {
+		fmt.Fprintln(ctx.Stderr, err)
+		return 1
+	}
+
+	// -vET
+	if c.all {
+		c.nonPrint = true
+		c.npTabs = true
+		c.npEnds = true
+	}
+	if c.npEnds {
+		c.ends = true
+	}
+	if c.blank {
+		c.number = true
+	}
+	if c.npTabs {
+		c.tabs = true
+	}
+	if c.all || c.npEnds || c.npTabs || c.nonPrint {
+		c.showNonPrinting = true
+	}
+	if !(c.number || c.ends || c.showNonPrinting || c.tabs || c.squeeze) {
+		c.simple = true
+	}
 
-	outStat, err := os.Stdout.Stat()
-	if err != nil {
-		fatal.Fatalln(err)
+	outFile, _ := ctx.Stdout.(*os.File)
+	outBsize := defaultBsize
+	var outStat os.FileInfo
+	var outReg bool
+	if outFile != nil {
+		stat, err := outFile.Stat()
+		if err != nil {
+			fmt.Fprintln(ctx.Stderr, err)
+			return 1
+		}
+		outStat = stat
+		outReg = stat.Mode().IsRegular()
+		outBsize = bsize(stat)
 	}
-	outReg := outStat.Mode().IsRegular()
-	outBsize := bsize(outStat)
 
 	// catch (./cat) < /etc/group
-	args := flag.Args()
-	if flag.NArg() == 0 {
-		args = []string{"-"}
+	files := c.f.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
 	}
 
-	var file *os.File
-	for _, arg := range args {
-		file = os.Stdin
+	pageSize := os.Getpagesize()
+
+	var ok int
+	for _, arg := range files {
+		file := ctx.Stdin
+		name := "-"
 		if arg != "-" {
-			file, err = os.Open(arg)
+			f, err := os.Open(arg)
 			if err != nil {
-				fatal.Fatalln(err)
+				fmt.Fprintln(ctx.Stderr, err)
+				ok = 1
+				continue
 			}
+			defer f.Close()
+			file = f
+			name = arg
 		}
 
-		inStat, err := file.Stat()
-		if err != nil {
-			fatal.Fatalln(err)
-		}
-		if inStat.IsDir() {
-			fatal.Printf("%s: is a directory\n", file.Name())
-		}
-		inBsize := bsize(inStat)
-
-		// prefetch! prefetch! prefetch!
-		unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
-
-		// Make sure we're not catting a file to itself,
-		// provided it's a regular file. Catting a non-reg
-		// file to itself is cool.
-		// e.g. cat file > file
-		if outReg && os.SameFile(outStat, inStat) {
-			if n, _ := file.Seek(0, os.SEEK_CUR); n < inStat.Size() {
-				fatal.Fatalf("%s: input file is output file\n", file.Name())
+		inFile, _ := file.(*os.File)
+		inBsize := defaultBsize
+		if inFile != nil {
+			inStat, err := inFile.Stat()
+			if err != nil {
+				fmt.Fprintln(ctx.Stderr, err)
+				ok = 1
+				continue
+			}
+			if inStat.IsDir() {
+				fmt.Fprintf(ctx.Stderr, "cat: %s: is a directory\n", name)
+				ok = 1
+				continue
+			}
+			inBsize = bsize(inStat)
+
+			// prefetch! prefetch! prefetch!
+			unix.Fadvise(int(inFile.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+
+			// Make sure we're not catting a file to itself,
+			// provided it's a regular file. Catting a non-reg
+			// file to itself is cool, and so is cat file >> file
+			// since the input is already past the bytes it wrote.
+			// e.g. cat file > file
+			if sameFileAsOutput(outStat, outReg, inFile, inStat) {
+				fmt.Fprintf(ctx.Stderr, "cat: %s: input file is output file\n", name)
+				ok = 1
+				continue
 			}
 		}
 
-		pageSize := os.Getpagesize()
-		if simple {
+		if c.simple {
 			// Select larger block size
 			size := max(inBsize, outBsize)
-			outBuf := bufio.NewWriterSize(os.Stdout, size+pageSize-1)
-			ok ^= simpleCat(file, outBuf)
-
-			// Flush because we don't have a chance to in
-			// simpleCat() because we use io.Copy()
+			outBuf := bufio.NewWriterSize(ctx.Stdout, size+pageSize-1)
+			ok ^= c.simpleCat(file, outBuf, inFile, outFile)
 			outBuf.Flush()
 		} else {
 			// If you want to know why, exactly, I chose
@@ -96,15 +150,18 @@ func main() {
 			// source code. The tl;dr is the 20 is the counter
 			// buffer, inBsize*4 is from potentially prepending
 			// the control characters (M-^), and outBsize is
-			// due to new tests for newlines.
+			// due to new tests for newlines. The *4 bound still
+			// covers --utf8 mode: a printable rune is written back
+			// byte-for-byte (ratio 1), a C1 control expands 2 input
+			// bytes into "M-^X" (ratio 2), and a lone invalid byte
+			// expands into "M-^X" on its own (ratio 4) -- never
+			// worse than the existing margin.
 			size := outBsize - 1 + inBsize*4 + 20
-			outBuf := bufio.NewWriterSize(os.Stdout, size)
+			outBuf := bufio.NewWriterSize(ctx.Stdout, size)
 			inBuf := make([]byte, inBsize+pageSize-1)
-			ok ^= cat(file, inBuf, outBuf)
+			ok ^= c.cat(file, inBuf, outBuf)
 		}
-
-		file.Close()
 	}
 
-	os.Exit(ok)
+	return ok
 }
@@ -0,0 +1,84 @@
+package cat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// runCat feeds input through c.cat() with the given input buffer size,
+// exercising the refill path (and, for small sizes, the carry path that
+// completes a rune split across a Read boundary) the same way the real
+// Run loops do.
+func runCat(t *testing.T, c *cmd, input string, bufSize int) string {
+	t.Helper()
+	var out bytes.Buffer
+	w := bufio.NewWriterSize(&out, 4096)
+	inBuf := make([]byte, bufSize+1) // +1 for the sentinel byte
+	if status := c.cat(strings.NewReader(input), inBuf, w); status != 0 {
+		t.Fatalf("cat() returned %d", status)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestCatUTF8NonPrinting(t *testing.T) {
+	// "héllo" (é = 0xC3 0xA9), a C1 control (0xC2 0x80, U+0080) and a
+	// lone invalid byte (0xFF) on a second line, with -n and -E both
+	// active. The result must be the same no matter how small a Read
+	// buffer chops up the multi-byte runes.
+	const input = "h\xc3\xa9llo\n\xc2\x80world\xff\n"
+	const want = "     1\th\u00e9llo$\n" +
+		"     2\tM-^@worldM-^?$\n"
+
+	for _, bufSize := range []int{4096, 16, 8, 4} {
+		t.Run(fmt.Sprintf("bufSize=%d", bufSize), func(t *testing.T) {
+			c := newCommand()
+			c.number = true
+			c.ends = true
+			c.showNonPrinting = true
+			c.utf8 = true
+
+			got := runCat(t, c, input, bufSize)
+			if got != want {
+				t.Errorf("bufSize=%d:\n got  %q\n want %q", bufSize, got, want)
+			}
+		})
+	}
+}
+
+func TestCatNonUTF8StillMangles(t *testing.T) {
+	// Same "é" with --utf8 off: the existing byte-by-byte M-/^ notation
+	// should mangle it exactly as it always has.
+	const input = "h\xc3\xa9llo\n"
+	const want = "hM-CM-)llo\n"
+
+	c := newCommand()
+	c.showNonPrinting = true
+	c.utf8 = false
+
+	got := runCat(t, c, input, 4096)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCatUTF8InvalidContinuation(t *testing.T) {
+	// 0xC3 is a valid two-byte lead, but '\n' isn't a continuation
+	// byte, so it's genuinely invalid UTF-8, not merely short.
+	const input = "ok\xc3\n"
+	const want = "okM-C\n"
+
+	c := newCommand()
+	c.showNonPrinting = true
+	c.utf8 = true
+
+	got := runCat(t, c, input, 4096)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+// +build windows
+
+// Copyright (c) 2014-2016 Eric Lagergren
+// Use of this source code is governed by the GPL v3 or later.
+
+package cat
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+
+	k32 "github.com/EricLagerg/go-gnulib/windows"
+)
+
+// clobberingOutputFallback re-checks whether in and out name the same
+// file by resolving both to their final path with
+// GetFinalPathNameByHandle, for the filesystems (some network mounts)
+// where os.SameFile's identity comparison can't be trusted. It's only
+// consulted when the primary, portable check in sameFileAsOutput
+// already came back false.
+func clobberingOutputFallback(in *os.File, inInfo os.FileInfo, out *os.File) bool {
+	inPath, err := finalPathName(in)
+	if err != nil {
+		return false
+	}
+	outPath, err := finalPathName(out)
+	if err != nil {
+		return false
+	}
+	if inPath != outPath {
+		return false
+	}
+
+	off, err := in.Seek(0, os.SEEK_CUR)
+	return err == nil && off < inInfo.Size()
+}
+
+func finalPathName(f *os.File) (string, error) {
+	buf := make([]byte, syscall.MAX_PATH)
+	if err := k32.GetFinalPathNameByHandleA(syscall.Handle(f.Fd()), buf, 0); err != nil {
+		return "", err
+	}
+	// buf is NUL-padded by the Win32 call; the original code compared
+	// the raw, untrimmed buffers, so two different paths padded to the
+	// same length would never be equal and a real match would rarely
+	// be detected either.
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,116 @@
+// +build !windows
+
+package cat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func mustStat(t *testing.T, name string) (*os.File, os.FileInfo) {
+	t.Helper()
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, info
+}
+
+func TestSameFileAsOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("same file", func(t *testing.T) {
+		name := filepath.Join(dir, "same.txt")
+		if err := os.WriteFile(name, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		in, inInfo := mustStat(t, name)
+		defer in.Close()
+		out, outInfo := mustStat(t, name)
+		defer out.Close()
+
+		if !sameFileAsOutput(outInfo, outInfo.Mode().IsRegular(), in, inInfo) {
+			t.Error("expected the same file, at offset 0, to be flagged as clobbering")
+		}
+	})
+
+	t.Run("hardlinked files", func(t *testing.T) {
+		name := filepath.Join(dir, "orig.txt")
+		link := filepath.Join(dir, "link.txt")
+		if err := os.WriteFile(name, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Link(name, link); err != nil {
+			t.Skipf("hard links unsupported here: %v", err)
+		}
+		in, inInfo := mustStat(t, name)
+		defer in.Close()
+		out, outInfo := mustStat(t, link)
+		defer out.Close()
+
+		if !sameFileAsOutput(outInfo, outInfo.Mode().IsRegular(), in, inInfo) {
+			t.Error("expected hardlinked files to be flagged as the same file")
+		}
+	})
+
+	t.Run("appended output", func(t *testing.T) {
+		name := filepath.Join(dir, "append.txt")
+		if err := os.WriteFile(name, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		in, inInfo := mustStat(t, name)
+		defer in.Close()
+		out, outInfo := mustStat(t, name)
+		defer out.Close()
+
+		// cat file >> file: the input is read, and the output is
+		// positioned at EOF once the append has caught up, so it's
+		// safe and must not be flagged.
+		if _, err := in.Seek(0, os.SEEK_END); err != nil {
+			t.Fatal(err)
+		}
+		if sameFileAsOutput(outInfo, outInfo.Mode().IsRegular(), in, inInfo) {
+			t.Error("append-mode cat to the same file must be permitted")
+		}
+	})
+
+	t.Run("pipes are allowed", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		defer w.Close()
+
+		inInfo, err := r.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		outInfo, err := w.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sameFileAsOutput(outInfo, outInfo.Mode().IsRegular(), r, inInfo) {
+			t.Error("a pipe's read and write ends must never be flagged as clobbering")
+		}
+	})
+
+	t.Run("character device", func(t *testing.T) {
+		in, inInfo := mustStat(t, os.DevNull)
+		defer in.Close()
+		out, outInfo := mustStat(t, os.DevNull)
+		defer out.Close()
+
+		// /dev/null is the same file on both ends, but it's not a
+		// regular file, so catting it to itself is always fine.
+		if sameFileAsOutput(outInfo, outInfo.Mode().IsRegular(), in, inInfo) {
+			t.Error("a character device must never be flagged as clobbering")
+		}
+	})
+}
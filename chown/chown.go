@@ -21,8 +21,6 @@
 	Written by Eric Lagergren <ericscottlagergren@gmail.com>
 */
 
-// BUG(eric): -R flag could get stuck in an infinite loop
-
 package main
 
 import (
@@ -41,14 +39,21 @@ var (
 	recursive      = flag.BoolP("recursive", "R", false, "")
 	changes        = flag.BoolP("changes", "c", false, "")
 	dereference    = flag.Bool("dereference", false, "")
-	from           = flag.Bool("from", false, "")
+	from           = flag.String("from", "", "")
 	noDereference  = flag.BoolP("no-dereference", "h", false, "")
 	noPreserveRoot = flag.Bool("no-preserve-root", false, "")
 	quiet          = flag.Bool("quiet", false, "")
 	silent         = flag.Bool("silent", false, "")
-	reference      = flag.Bool("reference", false, "")
+	reference      = flag.String("reference", "", "")
 	verbose        = flag.BoolP("verbose", "v", false, "")
 
+	journal  = flag.String("journal", "", "")
+	rollback = flag.String("rollback", "", "")
+
+	traverseCmdLine = flag.BoolP("H", "H", false, "")
+	traverseAll     = flag.BoolP("L", "L", false, "")
+	traverseNone    = flag.BoolP("P", "P", false, "")
+
 	version = flag.Bool("version", false, "")
 )
 
@@ -64,8 +69,152 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *rollback != "" {
+		if err := RollbackJournal(*rollback); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// 1 if --dereference, 0 if --no-dereference, -1 if neither
 	// has been specified.
 	// deref := -1
 	// bitFlags := 12 // todo
+
+	args := flag.Args()
+
+	opt := newChownOption()
+	opt.recurse = *recursive
+	opt.affectSymlinkReferent = !*noDereference
+	opt.forceSilent = *quiet || *silent
+	opt.noPreserveRoot = *noPreserveRoot
+
+	if *journal != "" {
+		jw, err := openJournal(*journal)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opt.journal = jw
+	}
+
+	switch {
+	case *verbose:
+		opt.verbosity = VHigh
+	case *changes:
+		opt.verbosity = VChangesOnly
+	default:
+		opt.verbosity = VOff
+	}
+
+	switch {
+	case *traverseAll:
+		opt.traversal = TraverseAll
+	case *traverseCmdLine:
+		opt.traversal = TraverseCommandLine
+	default:
+		opt.traversal = TraverseNone
+	}
+
+	if *from != "" {
+		uid, gid, err := ParseOwnerGroup(*from)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opt.fromUID, opt.fromGID = uid, gid
+	}
+
+	var uid, gid int
+	if *reference != "" {
+		var err error
+		uid, gid, err = ReferenceOwnership(*reference)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		if len(args) < 1 {
+			flag.Usage()
+		}
+		var err error
+		uid, gid, err = ParseOwnerGroup(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opt.userName, opt.groupName, _ = splitOwnerGroup(args[0])
+		args = args[1:]
+	}
+
+	if len(args) < 1 {
+		flag.Usage()
+	}
+
+	status := 0
+	for _, file := range args {
+		var err error
+		if opt.recurse {
+			err = RecursiveChown(file, opt, uid, gid)
+		} else {
+			st, statErr := os.Lstat(file)
+			if statErr != nil {
+				err = statErr
+			} else {
+				err = chownOne(file, st, opt, uid, gid)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "chown: %v\n", err)
+			status = 1
+		}
+	}
+	if opt.journal != nil {
+		if err := opt.journal.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "chown: %v\n", err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// splitOwnerGroup mirrors ParseOwnerGroup's OWNER[:GROUP] splitting, but
+// returns the raw name strings rather than resolved ids, so DescribeChange
+// can report the names the user actually typed instead of bare numbers.
+func splitOwnerGroup(spec string) (owner, group string, hasGroup bool) {
+	if owner, group, hasGroup = splitCut(spec, ':'); hasGroup {
+		return owner, group, true
+	}
+	return splitCut(spec, '.')
+}
+
+func splitCut(s string, sep byte) (before, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == sep {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// chownOne applies a single, non-recursive chown to file with a plain
+// os.Chown, or os.Lchown under --no-dereference, provided file's current
+// owner satisfies --from.
+func chownOne(file string, st os.FileInfo, opt *ChownOption, uid, gid int) error {
+	if !fromMatches(st, opt) {
+		return nil
+	}
+
+	var err error
+	if opt.affectSymlinkReferent {
+		err = os.Chown(file, uid, gid)
+	} else {
+		err = os.Lchown(file, uid, gid)
+	}
+
+	if opt.verbosity == VHigh {
+		reportChown(file, st, opt, uid, gid, err)
+	}
+	return err
 }
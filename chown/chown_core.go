@@ -12,6 +12,8 @@ func newChownOption() *ChownOption {
 	return &ChownOption{
 		verbosity:             VOff,
 		affectSymlinkReferent: true,
+		fromUID:               -1,
+		fromGID:               -1,
 	}
 }
 
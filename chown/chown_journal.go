@@ -0,0 +1,171 @@
+/*
+	Go chown -- change ownership of a file
+
+	Copyright (c) 2014-2015  Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// journalRecord is one entry in a --journal file: the path chowned, its
+// uid/gid before the change, and the uid/gid the change applied.
+type journalRecord struct {
+	path                           string
+	oldUID, oldGID, newUID, newGID int
+}
+
+// journalWriter appends journalRecords to an opened --journal file as
+// they happen, so an interrupted "chown -R --journal=PATH" can be
+// resumed (re-running with the same --journal=PATH skips every path
+// already recorded) or undone entirely with a later
+// "chown --rollback=PATH".
+//
+// Records are line-oriented, but the path itself is NUL-terminated
+// rather than newline-terminated, so a path containing a literal
+// newline still round-trips: the reader looks for the NUL to know
+// where the path ends and only then scans for the record's closing
+// '\n'.
+type journalWriter struct {
+	f    *os.File
+	done map[string]bool // paths already recorded before this run started
+}
+
+// openJournal opens path for appending, creating it if necessary, and
+// reads back any records it already holds from an earlier interrupted
+// run so the caller can skip those paths.
+func openJournal(path string) (*journalWriter, error) {
+	done, err := readJournalPaths(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &journalWriter{f: f, done: done}, nil
+}
+
+// alreadyDone reports whether path was recorded by a prior run of the
+// same --journal file, meaning this run should skip chowning it again.
+func (j *journalWriter) alreadyDone(path string) bool {
+	return j.done[path]
+}
+
+func (j *journalWriter) record(path string, oldUID, oldGID, newUID, newGID int) error {
+	_, err := fmt.Fprintf(j.f, "%s\x00%d\t%d\t%d\t%d\n", path, oldUID, oldGID, newUID, newGID)
+	return err
+}
+
+func (j *journalWriter) Close() error {
+	return j.f.Close()
+}
+
+// readJournalRecords parses every record out of a --journal file, in
+// the order they were written. A missing file is treated as an empty
+// journal rather than an error, since that's the normal state the
+// first time --journal=PATH is used.
+func readJournalRecords(path string) ([]journalRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []journalRecord
+	r := bufio.NewReader(f)
+	for {
+		p, err := r.ReadString(0)
+		if err == io.EOF {
+			if p != "" {
+				return nil, fmt.Errorf("chown: %s: truncated journal record", path)
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p = strings.TrimSuffix(p, "\x00")
+
+		rest, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		fields := strings.Fields(rest)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("chown: %s: malformed journal record for %q", path, p)
+		}
+		nums := make([]int, 4)
+		for i, s := range fields {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, fmt.Errorf("chown: %s: malformed journal record for %q: %v", path, p, err)
+			}
+			nums[i] = n
+		}
+		records = append(records, journalRecord{
+			path:   p,
+			oldUID: nums[0],
+			oldGID: nums[1],
+			newUID: nums[2],
+			newGID: nums[3],
+		})
+	}
+	return records, nil
+}
+
+// readJournalPaths reduces readJournalRecords to the set of paths it
+// holds, for --journal's resume check.
+func readJournalPaths(path string) (map[string]bool, error) {
+	records, err := readJournalRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(records))
+	for _, rec := range records {
+		done[rec.path] = true
+	}
+	return done, nil
+}
+
+// RollbackJournal restores every path recorded in the --journal file at
+// path to the ownership it had before the run that produced it,
+// undoing the most recently recorded change first.
+func RollbackJournal(path string) error {
+	records, err := readJournalRecords(path)
+	if err != nil {
+		return err
+	}
+	var status error
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		if err := os.Lchown(rec.path, rec.oldUID, rec.oldGID); err != nil {
+			fmt.Fprintf(os.Stderr, "chown: rollback: %v\n", err)
+			status = err
+		}
+	}
+	return status
+}
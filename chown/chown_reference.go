@@ -0,0 +1,92 @@
+/*
+	Go chown -- change ownership of a file
+
+	Copyright (c) 2014-2015  Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ReferenceOwnership stats rfile and returns the uid and gid --reference
+// should apply, in place of an OWNER[:GROUP] operand parsed off the
+// command line.
+func ReferenceOwnership(rfile string) (uid, gid int, err error) {
+	info, err := os.Stat(rfile)
+	if err != nil {
+		return 0, 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("chown: cannot determine ownership of %q", rfile)
+	}
+	return int(stat.Uid), int(stat.Gid), nil
+}
+
+// ParseOwnerGroup parses a chown OWNER[:GROUP] (or --from
+// CURRENT_OWNER:CURRENT_GROUP) operand into numeric uid/gid, returning
+// -1 for either half that's absent, so callers can tell "leave
+// unchanged" (user) apart from "don't check" (--from) from an explicit
+// request.
+func ParseOwnerGroup(spec string) (uid, gid int, err error) {
+	uid, gid = -1, -1
+
+	userPart, groupPart, hasGroup := strings.Cut(spec, ":")
+	if !hasGroup {
+		userPart, groupPart, hasGroup = strings.Cut(spec, ".")
+	}
+
+	if userPart != "" {
+		if uid, err = lookupUID(userPart); err != nil {
+			return -1, -1, err
+		}
+	}
+	if hasGroup && groupPart != "" {
+		if gid, err = lookupGID(groupPart); err != nil {
+			return -1, -1, err
+		}
+	}
+	return uid, gid, nil
+}
+
+func lookupUID(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	u, err := user.Lookup(s)
+	if err != nil {
+		return -1, fmt.Errorf("chown: invalid user: %q", s)
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+func lookupGID(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	g, err := user.LookupGroup(s)
+	if err != nil {
+		return -1, fmt.Errorf("chown: invalid group: %q", s)
+	}
+	return strconv.Atoi(g.Gid)
+}
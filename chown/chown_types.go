@@ -20,15 +20,41 @@ const (
 )
 
 type ChownOption struct {
-	verbosity Verbosity
-	recurse   bool
-	// rootDevIno *devIno
+	verbosity             Verbosity
+	recurse               bool
+	traversal             Traversal
 	affectSymlinkReferent bool
 	forceSilent           bool
 	userName              string
 	groupName             string
+
+	// fromUID and fromGID implement --from=CURRENT_OWNER:CURRENT_GROUP:
+	// an entry is only touched if its current uid/gid match these,
+	// where -1 means "don't check this half of the pair".
+	fromUID int
+	fromGID int
+
+	// noPreserveRoot disables RecursiveChown's refusal to descend into
+	// a root that resolves to the same (dev, ino) as /.
+	noPreserveRoot bool
+
+	// journal, if non-nil, makes every successful chown during a
+	// recursive run append a record of what it did (and skip any path
+	// a prior, interrupted run already recorded), so the run can be
+	// resumed with --journal=PATH or undone with --rollback=PATH.
+	journal *journalWriter
 }
 
+// Traversal selects which symlinks a recursive chown follows, mirroring
+// GNU chown's -H/-L/-P.
+type Traversal int
+
+const (
+	TraverseNone        Traversal = iota // -P (default): never follow symlinks
+	TraverseCommandLine                  // -H: follow a symlink if it's the recursion root itself
+	TraverseAll                          // -L: follow every symlink encountered during the walk
+)
+
 type RCHStatus int
 
 const (
@@ -0,0 +1,288 @@
+/*
+	Go chown -- change ownership of a file
+
+	Copyright (c) 2014-2015  Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/ericlagergren/go-coreutils/internal/fts"
+)
+
+// atSymlinkNoFollow is AT_SYMLINK_NOFOLLOW. The syscall package doesn't
+// export it, and this tool already pokes at raw syscall numbers
+// elsewhere (see RestrictedChown), so it's hardcoded here rather than
+// pulled in from golang.org/x/sys/unix for one constant.
+const atSymlinkNoFollow = 0x100
+
+// RecursiveChown walks root the way GNU chown -R does, applying uid and
+// gid (either of which may be -1 to leave that half unchanged) to root
+// and every entry beneath it that matches opt's --from predicate.
+//
+// The walk itself -- enumerating entries, deciding which symlinks to
+// follow, and detecting a followed symlink that loops back into one of
+// its own ancestors -- is internal/fts's job, the same traversal ls, du,
+// and any future find-like tool in this repo can use. What stays here
+// is the part fts can't do for us: every directory is also opened
+// relative to its already-open parent with syscall.Openat, and every
+// chown is performed with syscall.Fchownat against that parent fd
+// rather than by path, extending the Openat+Fchown pattern
+// RestrictedChown already uses for a single file. That keeps the
+// privileged operation itself safe against a symlink swapped into place
+// mid-walk, even though fts's own entry listing (like fts(3)'s) is
+// still a plain path-based read and can see a stale view of the tree.
+//
+// opt.traversal selects which symlinks are followed: TraverseNone (-P,
+// the default) follows none, so a symlink itself is chowned rather than
+// its target; TraverseCommandLine (-H) follows root if it's a symlink,
+// but no symlink found during the walk; TraverseAll (-L) follows every
+// symlink encountered, including one to a directory, which is then
+// descended into; a directory reached that way that loops back to one of
+// its own ancestors is reported by fts as Info == DC and not descended.
+//
+// Unless opt.noPreserveRoot is set, root is refused outright if it
+// resolves to the same (dev, ino) as /.
+func RecursiveChown(root string, opt *ChownOption, uid, gid int) error {
+	lst, err := os.Lstat(root)
+	if err != nil {
+		return err
+	}
+
+	if !opt.noPreserveRoot && isRootDir(lst) {
+		return fmt.Errorf("it is dangerous to operate recursively on %q (same as '/')\nuse --no-preserve-root to override this failsafe", root)
+	}
+
+	ftsOptions := fts.Physical
+	switch opt.traversal {
+	case TraverseAll:
+		ftsOptions = fts.Logical
+	case TraverseCommandLine:
+		ftsOptions = fts.ComFollow
+	}
+
+	f, err := fts.Open([]string{root}, ftsOptions)
+	if err != nil {
+		return err
+	}
+	defer fts.Close(f)
+
+	rootParentFd, err := syscall.Open(filepath.Dir(root), syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(rootParentFd)
+
+	// dirFds tracks the Openat'd fd for every directory fts currently
+	// has open on the path (keyed by the FTSENT fts itself uses to
+	// track that directory), so Fchownat always has a parent fd to
+	// operate against. The nil entry is root's own parent, opened
+	// above since fts has no FTSENT for it.
+	dirFds := map[*fts.FTSENT]int{nil: rootParentFd}
+	defer func() {
+		for ent, fd := range dirFds {
+			if ent != nil {
+				syscall.Close(fd)
+			}
+		}
+	}()
+
+	for {
+		ent, err := fts.Read(f)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch ent.Info {
+		case fts.DP:
+			if fd, ok := dirFds[ent]; ok {
+				syscall.Close(fd)
+				delete(dirFds, ent)
+			}
+			continue
+		case fts.DNR:
+			fmt.Fprintf(os.Stderr, "chown: cannot read directory %q: %v\n", ent.Path, ent.Errno)
+			continue
+		case fts.DC:
+			fmt.Fprintf(os.Stderr, "chown: %s: not chowning directory, as it was seen already (possible symlink loop)\n", ent.Path)
+			continue
+		case fts.NS, fts.ERR:
+			fmt.Fprintf(os.Stderr, "chown: %v\n", ent.Errno)
+			continue
+		}
+
+		parentFd, ok := dirFds[ent.Parent]
+		if !ok {
+			continue
+		}
+
+		if err := chownEntry(parentFd, ent, opt, uid, gid); err != nil {
+			fmt.Fprintf(os.Stderr, "chown: %v\n", err)
+		}
+
+		if ent.Info == fts.D {
+			dirFd, err := syscall.Openat(parentFd, ent.Name, syscall.O_RDONLY|syscall.O_DIRECTORY, 0)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "chown: %v\n", err)
+				continue
+			}
+			dirFds[ent] = dirFd
+		}
+	}
+}
+
+// isRootDir reports whether lst resolves to the same (dev, ino) as /,
+// the case --no-preserve-root guards against.
+func isRootDir(lst os.FileInfo) bool {
+	st, ok := lst.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	slashInfo, err := os.Stat("/")
+	if err != nil {
+		return false
+	}
+	slashSt, ok := slashInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return st.Dev == slashSt.Dev && st.Ino == slashSt.Ino
+}
+
+// chownEntry applies uid/gid to ent (opened relative to parentFd, the
+// already-open fd for ent's parent directory). Fchownat is told (via
+// AT_SYMLINK_NOFOLLOW) to act on a symlink itself rather than whatever
+// it points to wherever the traversal does not follow links: for every
+// entry under -P, for every entry below the root under -H, and for any
+// entry fts reports as SL or SLNONE.
+func chownEntry(parentFd int, ent *fts.FTSENT, opt *ChownOption, uid, gid int) error {
+	lst := ent.Stat
+	if lst == nil {
+		return nil
+	}
+
+	fullPath := ent.Path
+	if opt.journal != nil && opt.journal.alreadyDone(fullPath) {
+		return nil
+	}
+
+	if !fromMatches(lst, opt) {
+		return nil
+	}
+
+	flags := 0
+	if ent.Info == fts.SL || ent.Info == fts.SLNONE ||
+		opt.traversal == TraverseNone ||
+		(opt.traversal == TraverseCommandLine && ent.Level > 0) {
+		flags = atSymlinkNoFollow
+	}
+
+	var oldUID, oldGID uint32
+	if st, ok := lst.Sys().(*syscall.Stat_t); ok {
+		oldUID, oldGID = st.Uid, st.Gid
+	}
+
+	chownErr := syscall.Fchownat(parentFd, ent.Name, uid, gid, flags)
+	if opt.verbosity == VHigh {
+		reportChown(fullPath, lst, opt, uid, gid, chownErr)
+	}
+	if chownErr != nil && !opt.forceSilent {
+		fmt.Fprintf(os.Stderr, "chown: changing ownership of %q: %v\n", fullPath, chownErr)
+	}
+	if chownErr == nil && opt.journal != nil {
+		newUID, newGID := uid, gid
+		if newUID == -1 {
+			newUID = int(oldUID)
+		}
+		if newGID == -1 {
+			newGID = int(oldGID)
+		}
+		if err := opt.journal.record(fullPath, int(oldUID), int(oldGID), newUID, newGID); err != nil {
+			fmt.Fprintf(os.Stderr, "chown: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// fromMatches reports whether lst's current owner satisfies opt's
+// --from=CURRENT_OWNER:CURRENT_GROUP predicate (fromUID/fromGID of -1
+// mean that half isn't checked).
+func fromMatches(lst os.FileInfo, opt *ChownOption) bool {
+	if opt.fromUID == -1 && opt.fromGID == -1 {
+		return true
+	}
+	stat, ok := lst.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+	if opt.fromUID != -1 && uint32(opt.fromUID) != stat.Uid {
+		return false
+	}
+	if opt.fromGID != -1 && uint32(opt.fromGID) != stat.Gid {
+		return false
+	}
+	return true
+}
+
+// reportChown prints the same per-entry message DescribeChange does for
+// a single file, derived from lst's pre-chown owner and whether the
+// Fchownat call succeeded.
+func reportChown(path string, lst os.FileInfo, opt *ChownOption, uid, gid int, chownErr error) {
+	stat, ok := lst.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	changed := CHSucceeded
+	if chownErr != nil {
+		changed = CHFailed
+	} else if (uid == -1 || uint32(uid) == stat.Uid) && (gid == -1 || uint32(gid) == stat.Gid) {
+		changed = CHNoChangeRequested
+	}
+
+	DescribeChange(path, changed,
+		fmt.Sprintf("%d", stat.Uid), fmt.Sprintf("%d", stat.Gid),
+		userSpec(opt, uid), groupSpec(opt, gid))
+}
+
+func userSpec(opt *ChownOption, uid int) string {
+	if uid == -1 {
+		return ""
+	}
+	if opt.userName != "" {
+		return opt.userName
+	}
+	return fmt.Sprintf("%d", uid)
+}
+
+func groupSpec(opt *ChownOption, gid int) string {
+	if gid == -1 {
+		return ""
+	}
+	if opt.groupName != "" {
+		return opt.groupName
+	}
+	return fmt.Sprintf("%d", gid)
+}
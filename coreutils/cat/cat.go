@@ -25,7 +25,7 @@
 package main
 
 import (
-	"bytes"
+	"bufio"
 	"fmt"
 	"io"
 	"log"
@@ -71,15 +71,23 @@ Inspired by Torbjörn Granlund and Richard M. Stallman.
 `
 	Caret   = '^'
 	NewLine = 10 // \n
+
+	// readBufSize and writeBufSize size the bufio.Reader/Writer that
+	// front inFile and os.Stdout in the formatting path.
+	readBufSize  = 64 * 1024
+	writeBufSize = 64 * 1024
+
+	// scratchCap bounds catWriter's reusable transform buffer. Without
+	// a cap, a single line with no embedded newline (a binary file
+	// under -v, say) would make it grow via append for as long as the
+	// line lasted; instead it's flushed to the underlying writer and
+	// reused once it reaches this size.
+	scratchCap = 64 * 1024
 )
 
 var (
 	inFile *os.File
 	err    error
-	nlctr  int
-
-	Buffer    = make([]byte, 64*1024)
-	OutBuffer = make([]byte, 64*2048)
 
 	all          = flag.BoolP("show-all", "A", false, "equivalent to -vET\n")
 	nonBlank     = flag.BoolP("number-nonblank", "b", false, "number nonempty output lines, overrides -n\n")
@@ -96,58 +104,178 @@ var (
 	tabWriter = tabwriter.NewWriter(os.Stdout, 3, 0, 2, ' ', tabwriter.AlignRight)
 )
 
-func FormatOutput(line []byte, i uint64) {
+// catWriter reformats bytes read from a bufio.Reader according to the
+// active -n/-b/-E/-T/-v/-e/-t flags and writes the result to a
+// bufio.Writer. Lines are found directly in the bufio.Reader's own
+// internal buffer via ReadSlice instead of being copied into a
+// bytes.Buffer first, and the byte-by-byte caret/M- translation used
+// by -v/-e/-t writes into a reusable scratch slice that's flushed
+// whenever it reaches scratchCap, so a single very long line can't
+// grow it without bound.
+type catWriter struct {
+	out     *bufio.Writer
+	scratch []byte
+
+	lineNum     uint64
+	nlctr       int
+	atLineStart bool
+
+	bothEnds bool // -b or -n combined with -E
+	anyNp    bool // -v, -e, or -t: caret/M- notation is active
+}
 
-	// Check if line is a newline, and if so increment our counter
-	if len(line) != 0 && len(line) > 2 && line[0] == NewLine {
-		nlctr++
-	} else {
-		// If not, reset it
-		nlctr = 0
+func newCatWriter(w io.Writer, bothEnds, anyNp bool) *catWriter {
+	return &catWriter{
+		out:         bufio.NewWriterSize(w, writeBufSize),
+		scratch:     make([]byte, 0, scratchCap),
+		atLineStart: true,
+		bothEnds:    bothEnds,
+		anyNp:       anyNp,
+	}
+}
+
+func (cw *catWriter) Flush() error {
+	if len(cw.scratch) > 0 {
+		cw.out.Write(cw.scratch)
+		cw.scratch = cw.scratch[:0]
+	}
+	return cw.out.Flush()
+}
+
+// copyFrom drains br, handing one line (or, if a line is longer than
+// br's buffer, one buffer-sized fragment of it) at a time to
+// writeChunk. ReadSlice returning bufio.ErrBufferFull means the line
+// wasn't finished within br's buffer; the fragment it returned still
+// gets formatted, and atLineStart tracks whether the next fragment
+// continues the same line or starts a new one.
+func (cw *catWriter) copyFrom(br *bufio.Reader) error {
+	for {
+		chunk, err := br.ReadSlice(NewLine)
+		if len(chunk) > 0 {
+			cw.writeChunk(chunk)
+		}
+		switch err {
+		case nil, bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+func (cw *catWriter) writeChunk(chunk []byte) {
+	startOfLine := cw.atLineStart
+	cw.atLineStart = chunk[len(chunk)-1] == NewLine
+
+	if cw.anyNp || *showTabs {
+		cw.writeNonPrinting(chunk)
+		return
+	}
+	cw.writePlain(chunk, startOfLine)
+}
+
+// writePlain handles -n/-b/-E/-s, none of which change line content,
+// only what's printed around it.
+func (cw *catWriter) writePlain(line []byte, startOfLine bool) {
+	if startOfLine {
+		if len(line) > 2 && line[0] == NewLine {
+			cw.nlctr++
+		} else {
+			cw.nlctr = 0
+		}
 	}
 
-	// If we've seen a new line and -s is set, skip the next line
-	if nlctr > 1 && *squeezeBlank {
+	if cw.nlctr > 1 && *squeezeBlank {
 		return
-		// Print line number for non-blank lines
-	} else if *nonBlank && *showEnds || *nPEnds {
-		// Any char other than \n on a line with ONE char
+	}
+
+	if startOfLine &&
+		((cw.bothEnds || *number) ||
+			(*nonBlank && len(line) > 1 && line[0] != NewLine) ||
+			cw.lineNum == 0) {
+		cw.lineNum++
+	}
+
+	switch {
+	case *nonBlank && *showEnds:
 		if len(line) == 1 && line[0] != NewLine {
-			fmt.Printf("   %d %s$\n", i, line)
-			// Anything other than \n on the first space on
-			// the line
+			fmt.Fprintf(cw.out, "   %d %s$\n", cw.lineNum, line)
 		} else if line[0] != NewLine {
-			fmt.Printf("   %d  %s$\n", i, line[:len(line)-1])
-			// Just print the blank line
+			fmt.Fprintf(cw.out, "   %d  %s$\n", cw.lineNum, line[:len(line)-1])
 		} else {
-			fmt.Printf("%s$\n", line[:len(line)-1])
+			fmt.Fprintf(cw.out, "%s$\n", line[:len(line)-1])
 		}
-	} else if *nonBlank {
+	case *nonBlank:
 		if len(line) == 1 && line[0] != NewLine {
-			fmt.Printf("   %d %s\n", i, line)
+			fmt.Fprintf(cw.out, "   %d %s\n", cw.lineNum, line)
 		} else if line[0] != NewLine {
-			fmt.Printf("   %d  %s\n", i, line[:len(line)-1])
+			fmt.Fprintf(cw.out, "   %d  %s\n", cw.lineNum, line[:len(line)-1])
 		} else {
-			fmt.Printf("%s\n", line[:len(line)-1])
+			fmt.Fprintf(cw.out, "%s\n", line[:len(line)-1])
 		}
-		// For numbered lines
-	} else if *number {
+	case *number:
 		if len(line) == 1 && line[0] != NewLine {
-			fmt.Printf("   %d %s\n", i, line)
+			fmt.Fprintf(cw.out, "   %d %s\n", cw.lineNum, line)
 		} else {
-			fmt.Printf("   %d  %s\n", i, line[:len(line)-1])
+			fmt.Fprintf(cw.out, "   %d  %s\n", cw.lineNum, line[:len(line)-1])
 		}
-	} else if *showEnds || *all {
+	case *showEnds || *all:
 		if len(line) == 1 && line[0] == NewLine {
-			fmt.Println("$")
+			fmt.Fprintln(cw.out, "$")
 		} else if len(line) == 1 && line[0] != NewLine {
-			fmt.Printf("%s$\n", line)
+			fmt.Fprintf(cw.out, "%s$\n", line)
 		} else {
-			fmt.Printf("%s$\n", line[:len(line)-1])
+			fmt.Fprintf(cw.out, "%s$\n", line[:len(line)-1])
+		}
+	default:
+		cw.out.Write(line)
+	}
+}
+
+// writeNonPrinting handles -v/-e/-t, translating control and
+// high-bit-set bytes to ^X/M-X notation (except for TAB and LFD,
+// unless -T is also set) while streaming the result into cw.scratch.
+func (cw *catWriter) writeNonPrinting(chunk []byte) {
+	c := cw.scratch
+
+	for _, b := range chunk {
+		switch {
+		case b >= 32 && b < 127:
+			c = append(c, b)
+		case b == 127:
+			c = append(c, Caret, '?')
+		case b >= 128:
+			c = append(c, 'M', '-')
+			switch {
+			case b >= 128+32 && b < 128+127:
+				c = append(c, b-128)
+			case b == 128+127:
+				c = append(c, Caret, '?')
+			default:
+				c = append(c, Caret, b-128+64)
+			}
+		case b == 9 && !*showTabs:
+			c = append(c, 9)
+		case b == NewLine:
+			if *number || cw.bothEnds || (*nonBlank && len(c) != 0) {
+				cw.lineNum++
+			}
+			c = append(c, b)
+			cw.out.Write(c)
+			c = c[:0]
+		default:
+			c = append(c, Caret, b+64)
+		}
+
+		if len(c) >= scratchCap {
+			cw.out.Write(c)
+			c = c[:0]
 		}
-	} else {
-		fmt.Printf("%s", line)
 	}
+
+	cw.scratch = c
 }
 
 func Cat(fname string, stdin bool) {
@@ -172,119 +300,42 @@ func Cat(fname string, stdin bool) {
 		*showEnds = true
 	}
 
-	// Simple cat -- copy input to output with no formatting
+	// Simple cat -- copy input to output with no formatting.
 	if !(*number || *showEnds || *showTabs || *nP || *squeezeBlank || *all || *nonBlank || *nPTabs || *nPEnds) {
-		for {
-			_, err = io.Copy(os.Stdout, inFile)
-
-			if err == nil {
-				break
-			}
-		}
-		// For line numbers, line ends, or -s but nothing that changes the
-		// content of the strings (e.g. -T, -v)
-		//
-		// This saves some overhead if we're printing the line as-is, except
-		// with line numbers and/or line endings ($)
-	} else if !(anyNp || *showTabs) && (bothEnds || *showEnds || *number || *nonBlank || *squeezeBlank) {
-
-		// uint64 instead if int in case we have a file that exceeds
-		// 2147483647 lines unlikely, but why not be safe?
-		i := uint64(0)
-		for {
-			inBuffer, err := inFile.Read(Buffer)
-			buf := bytes.NewBuffer(Buffer[:inBuffer])
-
-			for {
-				line, err := buf.ReadBytes(NewLine)
-
-				// Catch when line is [] (happens at end of files when
-				// our buffer is empty for some reason)
-				if len(line) == 0 {
-					break
-				}
-
-				if (bothEnds || *number) ||
-					(*nonBlank && len(line) > 1 && line[0] != NewLine) ||
-					(i <= 0) {
-					i++
-				}
-
-				FormatOutput(line, i)
-
-				if err == io.EOF {
-					break
-				}
-			}
-
-			if err != nil {
-				break
-			}
+		if err := catSimple(inFile); err != nil {
+			log.Fatal(err)
 		}
-	} else {
-		i := uint64(0)
-		for {
-			inBuffer, err := inFile.Read(Buffer)
-			buf := bytes.NewBuffer(Buffer[:inBuffer])
-			c := OutBuffer
-
-			for {
-				b, err := buf.ReadByte()
-				if err == io.EOF {
-					break
-				}
-				if anyNp || *all {
-					if b >= 32 {
-						if b < 127 {
-							c = append(c, b)
-						} else if b == 127 {
-							c = append(c, Caret, '?')
-						} else {
-							c = append(c, 'M', '-')
-							if b >= 128+32 {
-								if b < 128+127 {
-									c = append(c, b-128)
-								} else {
-									c = append(c, Caret, '?')
-								}
-							} else {
-								c = append(c, Caret, b-128+64)
-							}
-						}
-					} else if b == 9 && !*showTabs {
-						c = append(c, 9)
-					} else if b == 10 {
-						if *number || bothEnds {
-							i++
-						}
-						c = append(c, b)
-						FormatOutput(c, i)
-						c = c[:0]
-					} else {
-						c = append(c, Caret, b+64)
-					}
-				} else {
-					if b == 9 && *showTabs {
-						c = append(c, Caret, b+64)
-					} else {
-						c = append(c, b)
-					}
-				}
-			}
-			if (bothEnds || *number) ||
-				(*nonBlank && len(c) != 0 && c[0] != NewLine) {
-				i++
-			}
-
-			FormatOutput(c, i)
+		return
+	}
 
-			if err == io.EOF {
-				break
-			}
+	cw := newCatWriter(os.Stdout, bothEnds, anyNp)
+	if err := cw.copyFrom(bufio.NewReaderSize(inFile, readBufSize)); err != nil {
+		log.Fatal(err)
+	}
+	if err := cw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
 
-		}
+// catSimple implements the no-flag fast path. When os.Stdout is a
+// kind fastCopy knows how to move bytes into straight from the
+// kernel's side (copy_file_range for regular->regular on the same
+// filesystem, sendfile for regular->socket, splice whenever a pipe is
+// involved), it does that; otherwise it falls back to a single
+// buffered io.Copy. The original implementation called io.Copy in a
+// loop that kept retrying as long as it returned a non-nil error,
+// which meant a genuine read/write error sent it spinning forever
+// instead of surfacing it; it now runs exactly once.
+func catSimple(inFile *os.File) error {
+	if _, err, ok := fastCopy(os.Stdout, inFile); ok {
+		return err
 	}
 
+	bw := bufio.NewWriterSize(os.Stdout, writeBufSize)
+	if _, err := io.Copy(bw, inFile); err != nil {
+		return err
+	}
+	return bw.Flush()
 }
 
 func main() {
@@ -0,0 +1,101 @@
+// Copyright (c) 2014-2016 Eric Lagergren
+// Use of this source code is governed by the GPL v3 or later.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+var benchFiles = []string{
+	"_testdata/lang_ru.txt",
+	"_testdata/dict_en.txt",
+	"_testdata/spaces_en.txt",
+	"_testdata/coreutils_man_en.txt",
+}
+
+// loadBenchCorpus concatenates benchFiles into one []byte, repeated
+// enough times to give the benchmarks a few hundred KiB to chew on.
+func loadBenchCorpus(b *testing.B) []byte {
+	b.Helper()
+	var one bytes.Buffer
+	for _, fn := range benchFiles {
+		data, err := os.ReadFile(fn)
+		if err != nil {
+			b.Fatal(err)
+		}
+		one.Write(data)
+	}
+
+	var corpus bytes.Buffer
+	for i := 0; i < 64; i++ {
+		corpus.Write(one.Bytes())
+	}
+	return corpus.Bytes()
+}
+
+// benchmarkCatWriter drives a catWriter over corpus the same way Cat
+// does for its formatting paths, discarding the formatted output.
+func benchmarkCatWriter(b *testing.B, corpus []byte, bothEnds, anyNp bool) {
+	b.Helper()
+	b.SetBytes(int64(len(corpus)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		cw := newCatWriter(io.Discard, bothEnds, anyNp)
+		if err := cw.copyFrom(bufio.NewReaderSize(bytes.NewReader(corpus), readBufSize)); err != nil {
+			b.Fatal(err)
+		}
+		if err := cw.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCatWriterPlainNumbered(b *testing.B) {
+	corpus := loadBenchCorpus(b)
+	*number = true
+	defer func() { *number = false }()
+
+	benchmarkCatWriter(b, corpus, false, false)
+}
+
+func BenchmarkCatWriterShowEnds(b *testing.B) {
+	corpus := loadBenchCorpus(b)
+	*showEnds = true
+	defer func() { *showEnds = false }()
+
+	benchmarkCatWriter(b, corpus, false, false)
+}
+
+func BenchmarkCatWriterNonPrinting(b *testing.B) {
+	corpus := loadBenchCorpus(b)
+	*nP = true
+	defer func() { *nP = false }()
+
+	benchmarkCatWriter(b, corpus, false, true)
+}
+
+// BenchmarkCatSimpleIOCopy measures the buffered io.Copy that catSimple
+// falls back to when fastCopy doesn't apply. catSimple itself needs an
+// *os.File, so the same copy is reproduced here over a bytes.Reader.
+// The cat package's copy of fastCopy is benchmarked against this kind
+// of copy in cat/cat_copy_linux_test.go on Linux.
+func BenchmarkCatSimpleIOCopy(b *testing.B) {
+	corpus := loadBenchCorpus(b)
+	b.SetBytes(int64(len(corpus)))
+
+	for i := 0; i < b.N; i++ {
+		bw := bufio.NewWriterSize(io.Discard, writeBufSize)
+		if _, err := io.Copy(bw, bytes.NewReader(corpus)); err != nil {
+			b.Fatal(err)
+		}
+		if err := bw.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,22 +1,13 @@
 package main
 
 import (
-	"fmt"
 	"os"
-	"strings"
+
+	iecho "github.com/ericlagergren/go-coreutils/internal/echo"
 )
 
 func main() {
-	fmt.Print(echo())
-}
-
-func echo() string {
-	// -n argument ommits the trailing new line
-	if len(os.Args) >= 2 && os.Args[1] == "-n" {
-		return fmt.Sprint(strings.Join(os.Args[2:], " "))
-
+	if err := iecho.Echo(os.Args[1:], os.Stdout); err != nil {
+		os.Exit(1)
 	}
-
-	// with trailing new line
-	return fmt.Sprintln(strings.Join(os.Args[1:], " "))
 }
@@ -0,0 +1,158 @@
+// Package echo implements the testable guts of the echo command: flag
+// parsing and XSI/GNU-style backslash escape processing, split out of
+// main so it can be exercised directly instead of through os.Args.
+package echo
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Echo writes args to out the way XSI/GNU echo does: words joined by a
+// single space, followed by a trailing newline unless -n was given.
+// Leading arguments made up entirely of the letters 'n', 'e', and 'E'
+// and prefixed with a single '-' are consumed as option groups (so
+// "-ne", "-en", and "-n -e" are all equivalent); the first argument
+// that isn't one of those stops option parsing, and everything from
+// there on is printed literally as an operand.
+//
+// -e turns on backslash escape interpretation, -E turns it back off;
+// whichever is given last wins. \c stops all output immediately,
+// including the trailing newline, even if later operands remain.
+func Echo(args []string, out io.Writer) error {
+	escapes, newline := false, true
+	i := 0
+	for ; i < len(args); i++ {
+		arg := args[i]
+		if !isOptionGroup(arg) {
+			break
+		}
+		for _, c := range arg[1:] {
+			switch c {
+			case 'n':
+				newline = false
+			case 'e':
+				escapes = true
+			case 'E':
+				escapes = false
+			}
+		}
+	}
+
+	for j, operand := range args[i:] {
+		if j > 0 {
+			if _, err := io.WriteString(out, " "); err != nil {
+				return err
+			}
+		}
+
+		text := operand
+		if escapes {
+			s, stop := unescape(operand)
+			text = s
+			if stop {
+				_, err := io.WriteString(out, text)
+				return err
+			}
+		}
+
+		if _, err := io.WriteString(out, text); err != nil {
+			return err
+		}
+	}
+
+	if !newline {
+		return nil
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// isOptionGroup reports whether arg is a "-n"/"-e"/"-E" style option
+// group: a single leading '-' followed by one or more of those letters
+// and nothing else.
+func isOptionGroup(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	for _, c := range arg[1:] {
+		if c != 'n' && c != 'e' && c != 'E' {
+			return false
+		}
+	}
+	return true
+}
+
+// unescape expands the backslash escapes XSI echo recognizes in s,
+// returning the expanded text and whether a \c was encountered --
+// callers must stop printing entirely (no trailing newline, no further
+// operands) when stop is true.
+func unescape(s string) (result string, stop bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'c':
+			return b.String(), true
+		case 'e':
+			b.WriteByte('\x1b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			n := 0
+			for n < 3 && i+1 < len(s) && isOctalDigit(s[i+1]) {
+				i++
+				n++
+			}
+			start := i - n + 1
+			if v, err := strconv.ParseUint(s[start:i+1], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+			}
+		case 'x':
+			n := 0
+			for n < 2 && i+1 < len(s) && isHexDigit(s[i+1]) {
+				i++
+				n++
+			}
+			if n == 0 {
+				b.WriteByte('\\')
+				b.WriteByte('x')
+				continue
+			}
+			start := i - n + 1
+			v, _ := strconv.ParseUint(s[start:i+1], 16, 8)
+			b.WriteByte(byte(v))
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), false
+}
+
+func isOctalDigit(c byte) bool { return c >= '0' && c <= '7' }
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,49 @@
+package echo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEcho(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no args", nil, "\n"},
+		{"plain words", []string{"Hello", "World"}, "Hello World\n"},
+		{"-n suppresses newline", []string{"-n", "Hello", "World"}, "Hello World"},
+		{"combined -ne", []string{"-ne", `a\tb`}, "a\tb"},
+		{"combined -en", []string{"-en", `a\tb`}, "a\tb"},
+		{"-E disables escapes again", []string{"-eE", `a\tb`}, `a\tb` + "\n"},
+		{"escapes off by default", []string{`a\tb`}, "a\\tb\n"},
+		{`backslash`, []string{"-e", `a\\b`}, "a\\b\n"},
+		{"alert", []string{"-e", `\a`}, "\a\n"},
+		{"backspace", []string{"-e", `\b`}, "\b\n"},
+		{`\c stops output`, []string{"-e", `ab\cde`, "more"}, "ab"},
+		{"escape char", []string{"-e", `\e`}, "\x1b\n"},
+		{"form feed", []string{"-e", `\f`}, "\f\n"},
+		{"newline", []string{"-e", `\n`}, "\n\n"},
+		{"carriage return", []string{"-e", `\r`}, "\r\n"},
+		{"tab", []string{"-e", `\t`}, "\t\n"},
+		{"vertical tab", []string{"-e", `\v`}, "\v\n"},
+		{"octal escape", []string{"-e", `\0101`}, "A\n"},
+		{"octal escape short", []string{"-e", `\01`}, "\x01\n"},
+		{"hex escape", []string{"-e", `\x41`}, "A\n"},
+		{"hex escape short", []string{"-e", `\x9`}, "\x09\n"},
+		{"unknown escape left alone", []string{"-e", `\q`}, "\\q\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := Echo(c.args, &buf); err != nil {
+				t.Fatalf("Echo(%v) error: %v", c.args, err)
+			}
+			if got := buf.String(); got != c.want {
+				t.Errorf("Echo(%v) = %q, want %q", c.args, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package encx
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"strings"
+)
+
+const (
+	base64Chars    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	base64URLChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	base32Chars    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	base32HexChars = "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+	base16Chars    = "0123456789ABCDEFabcdef"
+	pad            = '='
+)
+
+func alphabetTest(chars string, hasPad bool) func(byte) bool {
+	return func(b byte) bool {
+		if hasPad && b == pad {
+			return true
+		}
+		return strings.IndexByte(chars, b) >= 0
+	}
+}
+
+// Base64 is the standard RFC 4648 base64 alphabet ("+", "/").
+var Base64 = Alphabet{
+	Name:       "base64",
+	Enc:        base64.StdEncoding,
+	SrcBlock:   3,
+	IsAlphabet: alphabetTest(base64Chars, true),
+}
+
+// Base64URL is the RFC 4648 URL- and filename-safe base64 alphabet
+// ("-", "_").
+var Base64URL = Alphabet{
+	Name:       "base64url",
+	Enc:        base64.URLEncoding,
+	SrcBlock:   3,
+	IsAlphabet: alphabetTest(base64URLChars, true),
+}
+
+// Base32 is the standard RFC 4648 base32 alphabet.
+var Base32 = Alphabet{
+	Name:       "base32",
+	Enc:        base32.StdEncoding,
+	SrcBlock:   5,
+	IsAlphabet: alphabetTest(base32Chars, true),
+}
+
+// Base32Hex is RFC 4648's "Extended Hex" base32 alphabet, whose digits
+// sort the same as the values they represent.
+var Base32Hex = Alphabet{
+	Name:       "base32hex",
+	Enc:        base32.HexEncoding,
+	SrcBlock:   5,
+	IsAlphabet: alphabetTest(base32HexChars, true),
+}
+
+// Base16 is RFC 4648 base16 (hexadecimal), encoded as uppercase and
+// decoded case-insensitively.
+var Base16 = Alphabet{
+	Name:       "base16",
+	Enc:        hexEncoding{},
+	SrcBlock:   1,
+	IsAlphabet: alphabetTest(base16Chars, false),
+}
+
+// Z85 is the ZeroMQ Z85 alphabet; see z85.go for its padding caveat.
+var Z85 = Alphabet{
+	Name:       "z85",
+	Enc:        z85Encoding{},
+	SrcBlock:   4,
+	IsAlphabet: alphabetTest(z85Chars, false),
+}
+
+// Alphabets lists every alphabet Run can drive, keyed by the name
+// basenc's --NAME flags use.
+var Alphabets = map[string]Alphabet{
+	Base64.Name:    Base64,
+	Base64URL.Name: Base64URL,
+	Base32.Name:    Base32,
+	Base32Hex.Name: Base32Hex,
+	Base16.Name:    Base16,
+	Z85.Name:       Z85,
+}
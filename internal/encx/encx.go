@@ -0,0 +1,131 @@
+// Package encx is the shared streaming core behind base64, base32,
+// base16, and basenc: one block-oriented encode/decode pipeline driven
+// by a small Encoding interface, instead of a copy of the same
+// ReadAll-then-convert logic in every command.
+package encx
+
+import "io"
+
+// Encoding is the shape of a byte<->text codec that Run can drive
+// generically. *encoding/base64.Encoding and *encoding/base32.Encoding
+// already satisfy it as-is; base16 and z85 get small adapters (see
+// hex.go and z85.go).
+type Encoding interface {
+	Encode(dst, src []byte)
+	Decode(dst, src []byte) (int, error)
+	EncodedLen(n int) int
+	DecodedLen(n int) int
+}
+
+// Alphabet names one of the supported encodings and everything Run
+// needs to drive it.
+type Alphabet struct {
+	Name string
+	Enc  Encoding
+
+	// SrcBlock is the number of raw input bytes that make up one atom
+	// of this encoding -- 3 for base64, 5 for base32, 1 for base16, 4
+	// for z85. Encode is always called with a multiple of SrcBlock
+	// bytes, except for the final, possibly short, block.
+	SrcBlock int
+
+	// IsAlphabet reports whether b is a valid encoded-output byte for
+	// this alphabet (including its padding character, if any). It
+	// drives --ignore-garbage, so it must match the exact alphabet in
+	// use, not a general-purpose class like unicode.IsLetter.
+	IsAlphabet func(b byte) bool
+}
+
+// Options controls how Run processes a stream.
+type Options struct {
+	Decode        bool
+	IgnoreGarbage bool
+	Wrap          int // line width for encoded output; 0 disables wrapping
+}
+
+// blockAtoms is the number of atoms processed per internal read/write,
+// chosen to keep the streaming buffer in the tens of kilobytes
+// regardless of the alphabet's atom size.
+const blockAtoms = 1 << 12
+
+// Run encodes or decodes everything r has to offer into w, using
+// alphabet and opts, reading and writing in fixed-size blocks rather
+// than buffering the whole stream in memory.
+func Run(w io.Writer, r io.Reader, alphabet Alphabet, opts Options) error {
+	if opts.Decode {
+		return runDecode(w, r, alphabet, opts)
+	}
+	return runEncode(w, r, alphabet, opts)
+}
+
+func runEncode(w io.Writer, r io.Reader, alphabet Alphabet, opts Options) error {
+	var out io.Writer = w
+	var wrapped *wrapWriter
+	if opts.Wrap > 0 {
+		wrapped = newWrapWriter(w, opts.Wrap)
+		out = wrapped
+	}
+
+	src := make([]byte, alphabet.SrcBlock*blockAtoms)
+	dst := make([]byte, alphabet.Enc.EncodedLen(len(src)))
+	for {
+		n, err := io.ReadFull(r, src)
+		if n > 0 {
+			encN := alphabet.Enc.EncodedLen(n)
+			alphabet.Enc.Encode(dst[:encN], src[:n])
+			if _, werr := out.Write(dst[:encN]); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return err
+		}
+	}
+
+	if wrapped != nil {
+		return wrapped.Close()
+	}
+	// Wrap == 0: mirror the wrapped path's invariant that the output
+	// always ends with exactly one newline, even for empty input.
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func runDecode(w io.Writer, r io.Reader, alphabet Alphabet, opts Options) error {
+	var keep func(byte) bool
+	if opts.IgnoreGarbage {
+		keep = alphabet.IsAlphabet
+	} else {
+		// Even without --ignore-garbage, decoding has always tolerated
+		// embedded newlines from wrapped input; nothing in any of
+		// these alphabets uses '\r' or '\n' as a data character.
+		keep = func(b byte) bool { return b != '\n' && b != '\r' }
+	}
+	in := newFilterReader(r, keep)
+
+	atomChars := alphabet.Enc.EncodedLen(alphabet.SrcBlock)
+	src := make([]byte, atomChars*blockAtoms)
+	dst := make([]byte, alphabet.Enc.DecodedLen(len(src)))
+	for {
+		n, err := io.ReadFull(in, src)
+		if n > 0 {
+			decN, derr := alphabet.Enc.Decode(dst[:alphabet.Enc.DecodedLen(n)], src[:n])
+			if derr != nil {
+				return derr
+			}
+			if _, werr := w.Write(dst[:decN]); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package encx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func roundTrip(t *testing.T, alphabet Alphabet, in string, wrap int) {
+	t.Helper()
+
+	var enc bytes.Buffer
+	if err := Run(&enc, strings.NewReader(in), alphabet, Options{Wrap: wrap}); err != nil {
+		t.Fatalf("%s encode: %v", alphabet.Name, err)
+	}
+
+	var dec bytes.Buffer
+	if err := Run(&dec, strings.NewReader(enc.String()), alphabet, Options{Decode: true}); err != nil {
+		t.Fatalf("%s decode: %v", alphabet.Name, err)
+	}
+
+	if got := dec.String(); got != in {
+		t.Errorf("%s round trip (%q) == %q, want %q", alphabet.Name, in, got, in)
+	}
+}
+
+func TestRunRoundTrip(t *testing.T) {
+	inputs := []string{
+		"",
+		"f",
+		"hello world",
+		strings.Repeat("the quick brown fox jumps over the lazy dog. ", 200),
+	}
+
+	for _, alphabet := range Alphabets {
+		for _, in := range inputs {
+			if alphabet.Name == "z85" && len(in)%4 != 0 {
+				// z85 has no padding scheme; see z85.go.
+				continue
+			}
+			roundTrip(t, alphabet, in, 76)
+			roundTrip(t, alphabet, in, 0)
+		}
+	}
+}
+
+func TestRunDecodeIgnoreGarbage(t *testing.T) {
+	var dec bytes.Buffer
+	in := "aGVs!!!bG8gd@@@29ybGQ="
+	err := Run(&dec, strings.NewReader(in), Base64, Options{Decode: true, IgnoreGarbage: true})
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got, want := dec.String(), "hello world"; got != want {
+		t.Errorf("decode with garbage == %q, want %q", got, want)
+	}
+}
+
+func TestRunDecodeToleratesEmbeddedNewlines(t *testing.T) {
+	var dec bytes.Buffer
+	in := "aGVsbG8g\nd29ybGQ=\n"
+	if err := Run(&dec, strings.NewReader(in), Base64, Options{Decode: true}); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got, want := dec.String(), "hello world"; got != want {
+		t.Errorf("decode with embedded newlines == %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package encx
+
+import "io"
+
+// filterReader drops every byte for which keep returns false as data
+// streams through it. It backs both --ignore-garbage (keep is the
+// active alphabet's membership test) and the always-on newline
+// tolerance decoding has, without either one needing to buffer the
+// whole input first.
+type filterReader struct {
+	r    io.Reader
+	keep func(byte) bool
+
+	raw  []byte
+	kept []byte
+	pos  int
+	err  error
+}
+
+func newFilterReader(r io.Reader, keep func(byte) bool) *filterReader {
+	return &filterReader{
+		r:    r,
+		keep: keep,
+		raw:  make([]byte, 32*1024),
+		kept: make([]byte, 0, 32*1024),
+	}
+}
+
+func (f *filterReader) Read(p []byte) (int, error) {
+	for f.pos >= len(f.kept) {
+		if f.err != nil {
+			return 0, f.err
+		}
+
+		rn, rerr := f.r.Read(f.raw)
+		f.kept = f.kept[:cap(f.kept)]
+		k := 0
+		for i := 0; i < rn; i++ {
+			if f.keep(f.raw[i]) {
+				f.kept[k] = f.raw[i]
+				k++
+			}
+		}
+		f.kept = f.kept[:k]
+		f.pos = 0
+		f.err = rerr
+
+		if k > 0 {
+			break
+		}
+		// An all-garbage chunk isn't EOF; only return f.err once
+		// there's nothing kept left to deliver first.
+	}
+
+	n := copy(p, f.kept[f.pos:])
+	f.pos += n
+	return n, nil
+}
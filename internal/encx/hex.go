@@ -0,0 +1,23 @@
+package encx
+
+import "encoding/hex"
+
+// hexEncoding adapts encoding/hex's package-level functions to the
+// Encoding interface: hex.Encode returns a byte count Encoding.Encode
+// doesn't, and emits lowercase where base16 (per RFC 4648) wants
+// uppercase, so it needs a small wrapper rather than a direct alias.
+// hex.Decode already accepts either case, so Decode needs no change.
+type hexEncoding struct{}
+
+func (hexEncoding) Encode(dst, src []byte) {
+	n := hex.Encode(dst, src)
+	for i := 0; i < n; i++ {
+		if dst[i] >= 'a' && dst[i] <= 'f' {
+			dst[i] -= 'a' - 'A'
+		}
+	}
+}
+
+func (hexEncoding) Decode(dst, src []byte) (int, error) { return hex.Decode(dst, src) }
+func (hexEncoding) EncodedLen(n int) int                { return hex.EncodedLen(n) }
+func (hexEncoding) DecodedLen(n int) int                { return hex.DecodedLen(n) }
@@ -0,0 +1,60 @@
+package encx
+
+import "io"
+
+// wrapWriter inserts a newline after every wrap bytes written, as data
+// arrives, instead of formatting a complete in-memory buffer at once.
+// Close flushes the trailing newline for whatever partial line is left
+// open, matching the original wrapPrint's behavior of always ending
+// the output with exactly one newline -- even for empty input.
+type wrapWriter struct {
+	w     io.Writer
+	wrap  int
+	col   int
+	total int64
+}
+
+func newWrapWriter(w io.Writer, wrap int) *wrapWriter {
+	return &wrapWriter{w: w, wrap: wrap}
+}
+
+func (ww *wrapWriter) Write(p []byte) (int, error) {
+	total := len(p)
+	ww.total += int64(total)
+	for len(p) > 0 {
+		n := ww.wrap - ww.col
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := ww.w.Write(p[:n]); err != nil {
+			return 0, err
+		}
+		ww.col += n
+		p = p[n:]
+		if ww.col == ww.wrap {
+			if _, err := io.WriteString(ww.w, "\n"); err != nil {
+				return 0, err
+			}
+			ww.col = 0
+		}
+	}
+	return total, nil
+}
+
+func (ww *wrapWriter) Close() error {
+	switch {
+	case ww.total == 0:
+		// Nothing was ever written: still emit the lone newline
+		// wrapPrint produced for empty input.
+		_, err := io.WriteString(ww.w, "\n")
+		return err
+	case ww.col > 0:
+		// A partial final line is open; terminate it.
+		_, err := io.WriteString(ww.w, "\n")
+		return err
+	default:
+		// The output ended exactly on a wrap boundary, so the last
+		// full line already got its newline from Write.
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package encx
+
+import "fmt"
+
+// z85Chars is the ZeroMQ Z85 alphabet (rfc.zeromq.org/spec:32): 85
+// printable ASCII characters, each base-85 digit mapping to one byte.
+const z85Chars = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+
+var z85Decode [256]int8
+
+func init() {
+	for i := range z85Decode {
+		z85Decode[i] = -1
+	}
+	for i, c := range []byte(z85Chars) {
+		z85Decode[c] = int8(i)
+	}
+}
+
+// z85Encoding implements Encoding for Z85, which groups 4 input bytes
+// into 5 output characters. Z85 has no padding scheme of its own, so
+// -- unlike the other alphabets here -- a final input block shorter
+// than 4 bytes is zero-padded before encoding; round-tripping such
+// input back through decode will recover the original bytes plus
+// trailing zero padding, same as any other Z85 implementation.
+type z85Encoding struct{}
+
+func (z85Encoding) EncodedLen(n int) int { return (n + 3) / 4 * 5 }
+func (z85Encoding) DecodedLen(n int) int { return (n + 4) / 5 * 4 }
+
+func (z85Encoding) Encode(dst, src []byte) {
+	di := 0
+	for i := 0; i < len(src); i += 4 {
+		var v uint32
+		for j := 0; j < 4; j++ {
+			v <<= 8
+			if i+j < len(src) {
+				v |= uint32(src[i+j])
+			}
+		}
+		for j := 4; j >= 0; j-- {
+			dst[di+j] = z85Chars[v%85]
+			v /= 85
+		}
+		di += 5
+	}
+}
+
+func (z85Encoding) Decode(dst, src []byte) (int, error) {
+	if len(src)%5 != 0 {
+		return 0, fmt.Errorf("encx: z85 input length %d is not a multiple of 5", len(src))
+	}
+	di := 0
+	for i := 0; i < len(src); i += 5 {
+		var v uint32
+		for j := 0; j < 5; j++ {
+			d := z85Decode[src[i+j]]
+			if d < 0 {
+				return 0, fmt.Errorf("encx: invalid z85 character %q", src[i+j])
+			}
+			v = v*85 + uint32(d)
+		}
+		dst[di] = byte(v >> 24)
+		dst[di+1] = byte(v >> 16)
+		dst[di+2] = byte(v >> 8)
+		dst[di+3] = byte(v)
+		di += 4
+	}
+	return di, nil
+}
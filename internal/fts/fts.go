@@ -0,0 +1,471 @@
+// Package fts is a pure-Go reimplementation of BSD fts(3)'s hierarchy
+// traversal API, finished from the unusable FTS/FTSENT sketch that used
+// to live in the chown package (Build returned nothing, isLoop looped
+// forever, isSet inverted its test, and main called Build with the
+// wrong arity). It walks by path (Open/Stat/Lstat, not dirfd-relative
+// syscalls), detecting symlink loops via a visited (dev, ino) set the
+// way fts(3) itself does; it is not a TOCTOU-safe walk on its own.
+// chown's recursive walk (see chown_walk.go) uses it for enumeration
+// and loop detection, pairing it with its own Openat/Fchownat calls for
+// the part that does need to be TOCTOU-safe; any future find-like tool
+// in this repo can share the same traversal.
+package fts
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// Options, mirroring fts(3)'s FTS_* open flags.
+const (
+	ComFollow = 1 << iota // follow a symlink named on the command line
+	Logical               // logical walk: follow every symlink
+	NoChdir               // (accepted, unused: this implementation never chdirs)
+	NoStat                // don't stat entries; Info is NSOK, Stat is nil
+	Physical              // physical walk: follow no symlinks (the default)
+	SeeDot                // visit "." and ".." instead of skipping them
+	XDev                  // don't descend into directories on another device
+	Whiteout              // (accepted, unused: no union-mount whiteout support)
+
+	optionMask = ComFollow | Logical | NoChdir | NoStat | Physical | SeeDot | XDev | Whiteout
+)
+
+// Info reports what kind of node an FTSENT names and at which point of
+// its visit, mirroring fts(3)'s fts_info values.
+type Info int
+
+const (
+	_       Info = iota
+	D            // preorder: a directory, about to be descended into
+	DC           // a directory that would introduce a cycle; not descended
+	DEFAULT      // none of the others: a file of some other type
+	DNR          // a directory that could not be opened for reading
+	DOT          // "." or ".." (only seen with SeeDot)
+	DP           // postorder: a directory, all its descendants visited
+	ERR          // an error occurred; Errno is set
+	F            // a regular file
+	NS           // stat(2)/lstat(2) on this entry failed; Errno is set
+	NSOK         // NoStat was given: this entry was never stat'd
+	SL           // a symbolic link
+	SLNONE       // a symbolic link whose target does not exist
+)
+
+// Instructions Set accepts, mirroring fts(3)'s fts_set instructions.
+const (
+	_      = iota
+	Again  // revisit this entry on the next Read, from scratch
+	Follow // dereference this entry even though it's a symlink
+	Skip   // don't descend into (or revisit) this entry
+)
+
+// Children options.
+const (
+	// NameOnly tells Children not to stat the entries it returns,
+	// exactly as Open's NoStat does for the main walk.
+	NameOnly = 1 << iota
+)
+
+// Compare orders two siblings during a directory's traversal, the same
+// role fts_open's compar argument plays. Assign FTS.Compare before the
+// first Read to use one; the default is a plain name sort.
+type Compare func(a, b *FTSENT) int
+
+// FTSENT is one node produced by Read: a file, a directory (visited
+// twice -- once as D, once as DP -- unless Skip'd or broken somehow),
+// or a symlink.
+type FTSENT struct {
+	Parent *FTSENT // nil for a command-line operand
+	Link   *FTSENT // next sibling in this node's parent, or nil
+	Child  *FTSENT // first child, set by Children; nil otherwise
+
+	Name       string // base name of this entry
+	Path       string // path as built from the operand Open was given
+	AccessPath string // path to use to open or stat this entry
+
+	Info  Info
+	Errno error       // set when Info is NS, DNR, or ERR
+	Level int         // 0 for a command-line operand
+	Stat  os.FileInfo // nil when Info is NS or NSOK
+
+	instruction int  // pending Set instruction, consumed by the next Read
+	built       bool // children already attempted for this D entry
+	rootIndex   int  // which Open operand this entry descends from
+}
+
+// FTS holds the state of one traversal started by Open.
+type FTS struct {
+	options int
+	Compare Compare
+
+	pending []*FTSENT       // command-line operands not yet returned
+	stack   []*frame        // directories currently being descended
+	visited map[devIno]bool // devIno of every directory on the stack
+	rootDev map[int]uint64  // rootIndex -> starting device, for XDev
+	cur     *FTSENT
+}
+
+type frame struct {
+	dir       *FTSENT
+	children  []*FTSENT
+	idx       int
+	devIno    devIno
+	hasDevIno bool
+}
+
+type devIno struct {
+	dev, ino uint64
+}
+
+// Open begins a traversal rooted at each of paths, in the order given.
+// options is an OR of the Option constants; Physical is assumed if
+// neither Physical nor Logical is specified.
+func Open(paths []string, options int) (*FTS, error) {
+	if options & ^optionMask != 0 {
+		return nil, syscall.EINVAL
+	}
+	if options&Logical != 0 && options&Physical != 0 {
+		return nil, errors.New("fts: Logical and Physical are mutually exclusive")
+	}
+	if options&Logical == 0 && options&Physical == 0 {
+		options |= Physical
+	}
+
+	f := &FTS{
+		options: options,
+		visited: make(map[devIno]bool),
+		rootDev: make(map[int]uint64),
+	}
+
+	pending := make([]*FTSENT, len(paths))
+	for i, p := range paths {
+		ent := &FTSENT{
+			Name:       filepath.Base(p),
+			Path:       p,
+			AccessPath: p,
+			Level:      0,
+			rootIndex:  i,
+		}
+		fi, info, err := f.statEntry(p, true)
+		if err != nil {
+			ent.Errno = err
+			ent.Info = NS
+		} else {
+			ent.Stat = fi
+			ent.Info = info
+			if di, ok := devInoOf(fi); ok {
+				f.rootDev[i] = di.dev
+			}
+		}
+		pending[i] = ent
+	}
+	f.pending = pending
+	return f, nil
+}
+
+// Close releases any resources the traversal holds. This implementation
+// never keeps a directory descriptor open between Read calls, so Close
+// is always a no-op; it exists so callers don't need to know that.
+func Close(f *FTS) error {
+	return nil
+}
+
+// Read returns the next entry in the traversal: preorder (Info == D)
+// the first time a directory is seen, then every entry beneath it
+// (depth-first, siblings in Compare order), then that same directory a
+// second time with Info == DP. A directory that can't be opened is
+// returned a second time with Info == DNR instead of ever producing
+// children; one whose (device, inode) matches a directory still open
+// higher up the current path is returned a second time with Info == DC
+// and is likewise not descended into.
+//
+// Read returns (nil, io.EOF) once every operand and its descendants
+// have been produced.
+//
+// As in fts(3), a directory's preorder and postorder visits are the
+// same *FTSENT, with Info flipped from D to DP in place -- callers that
+// need to remember an entry past the next Read (as walkAll's tests do)
+// must copy the fields they care about rather than keep the pointer.
+func Read(f *FTS) (*FTSENT, error) {
+	if f.cur != nil {
+		switch f.cur.instruction {
+		case Again:
+			f.cur.instruction = 0
+			return f.cur, nil
+		case Follow:
+			f.cur.instruction = 0
+			f.followSymlink(f.cur)
+		case Skip:
+			f.cur.instruction = 0
+			f.cur.built = true
+		}
+
+		if f.cur.Info == D && !f.cur.built {
+			f.cur.built = true
+			action, children, err := f.buildChildren(f.cur)
+			switch action {
+			case buildUnreadable:
+				f.cur.Info = DNR
+				f.cur.Errno = err
+				return f.cur, nil
+			case buildCycle:
+				f.cur.Info = DC
+				return f.cur, nil
+			case buildXDev:
+				// Left as a leaf: not descended, not revisited.
+			case buildOK:
+				if len(children) == 0 {
+					f.cur.Info = DP
+					return f.cur, nil
+				}
+				di, hasDevIno := devInoOf(f.cur.Stat)
+				fr := &frame{dir: f.cur, children: children, devIno: di, hasDevIno: hasDevIno}
+				if hasDevIno {
+					f.visited[di] = true
+				}
+				f.stack = append(f.stack, fr)
+			}
+		}
+	}
+
+	for {
+		if n := len(f.stack); n > 0 {
+			top := f.stack[n-1]
+			if top.idx < len(top.children) {
+				child := top.children[top.idx]
+				top.idx++
+				f.cur = child
+				return child, nil
+			}
+			f.stack = f.stack[:n-1]
+			if top.hasDevIno {
+				delete(f.visited, top.devIno)
+			}
+			top.dir.Info = DP
+			f.cur = top.dir
+			return top.dir, nil
+		}
+		if len(f.pending) == 0 {
+			f.cur = nil
+			return nil, io.EOF
+		}
+		next := f.pending[0]
+		f.pending = f.pending[1:]
+		f.cur = next
+		return next, nil
+	}
+}
+
+// Children returns the linked list (via FTSENT.Link) of the current
+// entry's children, the same set Read would otherwise deliver one at a
+// time, without disturbing the traversal's own state. It's meant for
+// callers that want to inspect, sort, or filter a directory's entries
+// themselves (e.g. "ls -f") rather than have Read walk them.
+func Children(f *FTS, options int) (*FTSENT, error) {
+	if f.cur == nil {
+		return nil, errors.New("fts: Children called before the first Read")
+	}
+
+	saved := f.options
+	if options&NameOnly != 0 {
+		f.options |= NoStat
+	}
+	action, children, err := f.buildChildren(f.cur)
+	f.options = saved
+
+	switch action {
+	case buildUnreadable:
+		return nil, err
+	case buildCycle, buildXDev:
+		return nil, nil
+	}
+	for i := 0; i+1 < len(children); i++ {
+		children[i].Link = children[i+1]
+	}
+	f.cur.Child = nil
+	if len(children) > 0 {
+		f.cur.Child = children[0]
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return children[0], nil
+}
+
+// Set attaches instr to p, to take effect the next time Read is asked
+// to advance past p (normally the very next call).
+func Set(f *FTS, p *FTSENT, instr int) error {
+	switch instr {
+	case Again, Follow, Skip:
+		p.instruction = instr
+		return nil
+	default:
+		return fmt.Errorf("fts: invalid instruction %d", instr)
+	}
+}
+
+type buildAction int
+
+const (
+	buildOK buildAction = iota
+	buildUnreadable
+	buildCycle
+	buildXDev
+)
+
+// buildChildren reads parent's directory entries (applying SeeDot and
+// XDev, checking for a symlink-induced cycle via dev/ino, and stat'ing
+// each child per f.options) and returns them in traversal order.
+func (f *FTS) buildChildren(parent *FTSENT) (buildAction, []*FTSENT, error) {
+	if f.options&XDev != 0 && parent.Level > 0 {
+		if rootDev, ok := f.rootDev[parent.rootIndex]; ok {
+			if pdi, ok := devInoOf(parent.Stat); ok && pdi.dev != rootDev {
+				return buildXDev, nil, nil
+			}
+		}
+	}
+
+	if di, ok := devInoOf(parent.Stat); ok && f.visited[di] {
+		return buildCycle, nil, nil
+	}
+
+	dh, err := os.Open(parent.AccessPath)
+	if err != nil {
+		return buildUnreadable, nil, err
+	}
+	names, err := dh.Readdirnames(-1)
+	dh.Close()
+	if err != nil {
+		return buildUnreadable, nil, err
+	}
+
+	if f.options&SeeDot != 0 {
+		// Readdirnames never reports "." or "..", so SeeDot adds them.
+		names = append(names, ".", "..")
+	} else {
+		filtered := names[:0]
+		for _, n := range names {
+			if n != "." && n != ".." {
+				filtered = append(filtered, n)
+			}
+		}
+		names = filtered
+	}
+	sort.Strings(names)
+
+	children := make([]*FTSENT, len(names))
+	for i, name := range names {
+		children[i] = f.newEntry(parent, name)
+	}
+	if f.Compare != nil {
+		sort.Slice(children, func(i, j int) bool {
+			return f.Compare(children[i], children[j]) < 0
+		})
+	}
+	return buildOK, children, nil
+}
+
+// newEntry builds the FTSENT for name, a child of parent, stat'ing it
+// (unless NoStat is set) to decide its Info. It never itself recurses:
+// a directory's own children aren't read until it's handed back to
+// Read as the current entry.
+func (f *FTS) newEntry(parent *FTSENT, name string) *FTSENT {
+	ent := &FTSENT{
+		Parent:    parent,
+		Name:      name,
+		Path:      filepath.Join(parent.Path, name),
+		Level:     parent.Level + 1,
+		rootIndex: parent.rootIndex,
+	}
+	ent.AccessPath = filepath.Join(parent.AccessPath, name)
+
+	if name == "." || name == ".." {
+		ent.Info = DOT
+		return ent
+	}
+
+	fi, info, err := f.statEntry(ent.AccessPath, false)
+	if err != nil {
+		ent.Errno = err
+		ent.Info = NS
+		return ent
+	}
+	ent.Stat = fi
+	ent.Info = info
+	return ent
+}
+
+// statEntry stats path per f.options: Logical dereferences every
+// symlink, ComFollow dereferences only a command-line operand
+// (rootLevel), and otherwise (Physical) symlinks are left alone and
+// reported as SL or SLNONE. NoStat skips the syscall entirely.
+func (f *FTS) statEntry(path string, rootLevel bool) (os.FileInfo, Info, error) {
+	if f.options&NoStat != 0 {
+		return nil, NSOK, nil
+	}
+
+	follow := f.options&Logical != 0 || (rootLevel && f.options&ComFollow != 0)
+	var fi os.FileInfo
+	var err error
+	if follow {
+		fi, err = os.Stat(path)
+	} else {
+		fi, err = os.Lstat(path)
+	}
+	if err != nil {
+		return nil, NS, err
+	}
+
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		if _, statErr := os.Stat(path); statErr != nil {
+			return fi, SLNONE, nil
+		}
+		return fi, SL, nil
+	case fi.IsDir():
+		return fi, D, nil
+	case fi.Mode().IsRegular():
+		return fi, F, nil
+	default:
+		return fi, DEFAULT, nil
+	}
+}
+
+// followSymlink dereferences ent in place for the Follow instruction,
+// turning a symlink to a directory into a D entry so the next Read
+// descends into it.
+func (f *FTS) followSymlink(ent *FTSENT) {
+	fi, err := os.Stat(ent.AccessPath)
+	if err != nil {
+		ent.Info = NS
+		ent.Errno = err
+		return
+	}
+	ent.Stat = fi
+	switch {
+	case fi.IsDir():
+		ent.Info = D
+		ent.built = false
+	case fi.Mode().IsRegular():
+		ent.Info = F
+	default:
+		ent.Info = DEFAULT
+	}
+}
+
+// devInoOf extracts the (device, inode) pair backing fi, when fi came
+// from a platform whose os.FileInfo.Sys is a *syscall.Stat_t.
+func devInoOf(fi os.FileInfo) (devIno, bool) {
+	if fi == nil {
+		return devIno{}, false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return devIno{}, false
+	}
+	return devIno{dev: uint64(st.Dev), ino: st.Ino}, true
+}
@@ -0,0 +1,315 @@
+package fts
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// visit is a point-in-time snapshot of the fields a test cares about.
+// Read reuses the same *FTSENT for a directory's preorder and
+// postorder visits (as fts(3) does), flipping Info from D to DP in
+// place, so a test collecting every visit for later inspection must
+// copy out what it needs rather than keep the pointer.
+type visit struct {
+	path  string
+	name  string
+	info  Info
+	errno error
+}
+
+func walkAll(t *testing.T, f *FTS) []visit {
+	t.Helper()
+	var got []visit
+	for {
+		ent, err := Read(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		got = append(got, visit{path: ent.Path, name: ent.Name, info: ent.Info, errno: ent.Errno})
+	}
+	return got
+}
+
+func TestReadDeepTree(t *testing.T) {
+	root := t.TempDir()
+	must(t, os.MkdirAll(filepath.Join(root, "a", "b", "c"), 0755))
+	must(t, os.WriteFile(filepath.Join(root, "a", "f1"), []byte("x"), 0644))
+	must(t, os.WriteFile(filepath.Join(root, "a", "b", "f2"), []byte("y"), 0644))
+	must(t, os.WriteFile(filepath.Join(root, "a", "b", "c", "f3"), []byte("z"), 0644))
+
+	f, err := Open([]string{root}, Physical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	ents := walkAll(t, f)
+
+	var dCount, dpCount, fCount int
+	seenPaths := map[string]bool{}
+	for _, e := range ents {
+		switch e.info {
+		case D:
+			dCount++
+		case DP:
+			dpCount++
+		case F:
+			fCount++
+		}
+		seenPaths[e.path] = true
+	}
+	if dCount != dpCount {
+		t.Fatalf("unbalanced D/DP: D=%d DP=%d", dCount, dpCount)
+	}
+	// root + a + b + c = 4 directories.
+	if dCount != 4 {
+		t.Fatalf("dCount = %d, want 4", dCount)
+	}
+	if fCount != 3 {
+		t.Fatalf("fCount = %d, want 3", fCount)
+	}
+	for _, want := range []string{
+		root,
+		filepath.Join(root, "a"),
+		filepath.Join(root, "a", "f1"),
+		filepath.Join(root, "a", "b"),
+		filepath.Join(root, "a", "b", "f2"),
+		filepath.Join(root, "a", "b", "c"),
+		filepath.Join(root, "a", "b", "c", "f3"),
+	} {
+		if !seenPaths[want] {
+			t.Errorf("missing path %q in traversal", want)
+		}
+	}
+
+	// A directory's DP must come after every entry beneath it, and its
+	// D before every entry beneath it.
+	indexOf := func(path string, info Info) int {
+		for i, e := range ents {
+			if e.path == path && e.info == info {
+				return i
+			}
+		}
+		return -1
+	}
+	rootD, rootDP := indexOf(root, D), indexOf(root, DP)
+	f3Idx := -1
+	for i, e := range ents {
+		if e.path == filepath.Join(root, "a", "b", "c", "f3") {
+			f3Idx = i
+		}
+	}
+	if !(rootD < f3Idx && f3Idx < rootDP) {
+		t.Fatalf("f3 (%d) not nested between root D (%d) and DP (%d)", f3Idx, rootD, rootDP)
+	}
+}
+
+func TestReadSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	must(t, os.Mkdir(filepath.Join(root, "dir"), 0755))
+	must(t, os.Symlink(root, filepath.Join(root, "dir", "loop")))
+
+	f, err := Open([]string{root}, Logical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	ents := walkAll(t, f)
+
+	var dc int
+	for _, e := range ents {
+		if e.info == DC {
+			dc++
+		}
+	}
+	if dc != 1 {
+		t.Fatalf("got %d DC entries, want exactly 1 (the loop back to %s)", dc, root)
+	}
+}
+
+func TestReadUnreadableDir(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can read anything; permission-denial test needs a non-root uid")
+	}
+
+	root := t.TempDir()
+	locked := filepath.Join(root, "locked")
+	must(t, os.Mkdir(locked, 0000))
+	defer os.Chmod(locked, 0755)
+
+	f, err := Open([]string{root}, Physical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	ents := walkAll(t, f)
+
+	var dnr *visit
+	for i, e := range ents {
+		if e.path == locked && e.info == DNR {
+			dnr = &ents[i]
+		}
+	}
+	if dnr == nil {
+		t.Fatal("locked directory was never reported as DNR")
+	}
+	if dnr.errno == nil {
+		t.Error("DNR entry has a nil Errno")
+	}
+}
+
+func TestSetSkip(t *testing.T) {
+	root := t.TempDir()
+	must(t, os.Mkdir(filepath.Join(root, "skipme"), 0755))
+	must(t, os.WriteFile(filepath.Join(root, "skipme", "hidden"), []byte("x"), 0644))
+	must(t, os.Mkdir(filepath.Join(root, "keep"), 0755))
+	must(t, os.WriteFile(filepath.Join(root, "keep", "visible"), []byte("x"), 0644))
+
+	f, err := Open([]string{root}, Physical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	var got []string
+	for {
+		ent, err := Read(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ent.Info == D && ent.Name == "skipme" {
+			if err := Set(f, ent, Skip); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got = append(got, ent.Name)
+	}
+
+	for _, name := range got {
+		if name == "hidden" {
+			t.Fatalf("Skip on skipme did not prevent descending into it: %v", got)
+		}
+	}
+	var sawVisible bool
+	for _, name := range got {
+		if name == "visible" {
+			sawVisible = true
+		}
+	}
+	if !sawVisible {
+		t.Fatalf("Skip on skipme incorrectly suppressed the unrelated keep subtree: %v", got)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	root := t.TempDir()
+	must(t, os.WriteFile(filepath.Join(root, "b.txt"), []byte("x"), 0644))
+	must(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("x"), 0644))
+	must(t, os.Mkdir(filepath.Join(root, "c"), 0755))
+
+	f, err := Open([]string{root}, Physical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	ent, err := Read(f) // the root itself
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ent.Info != D {
+		t.Fatalf("first entry Info = %v, want D", ent.Info)
+	}
+
+	head, err := Children(f, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for c := head; c != nil; c = c.Link {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	want := []string{"a.txt", "b.txt", "c"}
+	if len(names) != len(want) {
+		t.Fatalf("Children names = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Children names = %v, want %v", names, want)
+		}
+	}
+}
+
+func TestXDevBoundary(t *testing.T) {
+	root := t.TempDir()
+	must(t, os.Mkdir(filepath.Join(root, "same"), 0755))
+	must(t, os.WriteFile(filepath.Join(root, "same", "f"), []byte("x"), 0644))
+
+	f, err := Open([]string{root}, Physical|XDev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(f)
+
+	rootEnt, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stand in for a subdirectory on another device with a synthetic
+	// entry (its path need not exist, since the XDev check returns before
+	// the directory is opened): exercises buildChildren's XDev check
+	// without requiring a real second filesystem in the sandbox.
+	other := &FTSENT{
+		Parent:     rootEnt,
+		Name:       "other",
+		Path:       filepath.Join(root, "other"),
+		AccessPath: filepath.Join(root, "other"),
+		Level:      1,
+		rootIndex:  rootEnt.rootIndex,
+		Info:       D,
+		Stat:       fakeDirInfo{dev: f.rootDev[rootEnt.rootIndex] + 1},
+	}
+	action, children, _ := f.buildChildren(other)
+	if action != buildXDev {
+		t.Fatalf("buildChildren action = %v, want buildXDev", action)
+	}
+	if children != nil {
+		t.Fatalf("buildChildren returned children for an XDev-skipped directory: %v", children)
+	}
+}
+
+// fakeDirInfo is a minimal os.FileInfo standing in for a directory on a
+// device distinct from the traversal root, so TestXDevBoundary doesn't
+// need a real second filesystem to exercise the XDev check.
+type fakeDirInfo struct {
+	dev uint64
+}
+
+func (fakeDirInfo) Name() string       { return "other" }
+func (fakeDirInfo) Size() int64        { return 0 }
+func (fakeDirInfo) Mode() os.FileMode  { return os.ModeDir | 0755 }
+func (fakeDirInfo) ModTime() time.Time { return time.Time{} }
+func (fakeDirInfo) IsDir() bool        { return true }
+func (f fakeDirInfo) Sys() interface{} { return &syscall.Stat_t{Dev: f.dev, Ino: 1} }
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package uptime abstracts the platform-specific bits the uptime command
+// (and, eventually, w / who -b) needs: how long the system has been up,
+// when it booted, and its recent scheduling load. Each OS gets its own
+// New() in a build-tagged file; callers only ever see the Source
+// interface.
+package uptime
+
+import "time"
+
+// Source reports the boot time, elapsed uptime, and load averages of
+// the current machine.
+type Source interface {
+	// BootTime returns the time the system booted.
+	BootTime() (time.Time, error)
+
+	// Uptime returns how long the system has been running.
+	Uptime() (time.Duration, error)
+
+	// LoadAvg returns the 1, 5, and 15 minute load averages, in that
+	// order.
+	LoadAvg() ([3]float64, error)
+}
+
+// FakeSource is a Source with fixed results, for golden-testing code
+// that formats a Source's output without depending on the machine the
+// test happens to run on.
+type FakeSource struct {
+	Boot time.Time
+	Up   time.Duration
+	Avg  [3]float64
+	Err  error
+}
+
+func (f FakeSource) BootTime() (time.Time, error) { return f.Boot, f.Err }
+
+func (f FakeSource) Uptime() (time.Duration, error) { return f.Up, f.Err }
+
+func (f FakeSource) LoadAvg() ([3]float64, error) { return f.Avg, f.Err }
@@ -0,0 +1,63 @@
+//go:build darwin
+// +build darwin
+
+package uptime
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// darwinLoadavg mirrors xnu's struct loadavg (sys/resource.h): three
+// fixed-point load averages scaled by fscale.
+type darwinLoadavg struct {
+	ldavg  [3]uint32
+	fscale uint64
+}
+
+type darwinSource struct{}
+
+// New returns the Source for the current platform.
+func New() Source {
+	return darwinSource{}
+}
+
+func (darwinSource) BootTime() (time.Time, error) {
+	raw, err := unix.SysctlRaw("kern.boottime")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(raw) < int(unsafe.Sizeof(unix.Timeval{})) {
+		return time.Time{}, fmt.Errorf("uptime: kern.boottime: short read (%d bytes)", len(raw))
+	}
+	tv := *(*unix.Timeval)(unsafe.Pointer(&raw[0]))
+	return time.Unix(int64(tv.Sec), int64(tv.Usec)*1000), nil
+}
+
+func (s darwinSource) Uptime() (time.Duration, error) {
+	boot, err := s.BootTime()
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(boot), nil
+}
+
+func (darwinSource) LoadAvg() ([3]float64, error) {
+	var avg [3]float64
+
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return avg, err
+	}
+	if len(raw) < int(unsafe.Sizeof(darwinLoadavg{})) {
+		return avg, fmt.Errorf("uptime: vm.loadavg: short read (%d bytes)", len(raw))
+	}
+	l := *(*darwinLoadavg)(unsafe.Pointer(&raw[0]))
+	for i, v := range l.ldavg {
+		avg[i] = float64(v) / float64(l.fscale)
+	}
+	return avg, nil
+}
@@ -0,0 +1,67 @@
+//go:build linux
+// +build linux
+
+package uptime
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// linuxSource reads /proc/uptime and /proc/loadavg, the same files
+// go-gnulib's stdlib.GetLoadAvg parses for the load average half of
+// this.
+type linuxSource struct{}
+
+// New returns the Source for the current platform.
+func New() Source {
+	return linuxSource{}
+}
+
+func (linuxSource) Uptime() (time.Duration, error) {
+	f, err := os.Open("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var secs float64
+	if _, err := fmt.Fscanf(f, "%f", &secs); err != nil {
+		return 0, fmt.Errorf("uptime: /proc/uptime: %v", err)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+func (s linuxSource) BootTime() (time.Time, error) {
+	up, err := s.Uptime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-up), nil
+}
+
+func (linuxSource) LoadAvg() ([3]float64, error) {
+	var avg [3]float64
+
+	f, err := os.Open("/proc/loadavg")
+	if err != nil {
+		return avg, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Split(bufio.ScanWords)
+	for i := range avg {
+		if !sc.Scan() {
+			return avg, fmt.Errorf("uptime: /proc/loadavg: missing field %d", i)
+		}
+		avg[i], err = strconv.ParseFloat(sc.Text(), 64)
+		if err != nil {
+			return avg, fmt.Errorf("uptime: /proc/loadavg: %v", err)
+		}
+	}
+	return avg, nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package uptime
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procGetTickCount64 = kernel32.NewProc("GetTickCount64")
+)
+
+// errNoLoadAvg is returned by windowsSource.LoadAvg: Windows has no
+// equivalent of getloadavg(3) or vm.loadavg, so there's nothing to
+// report.
+var errNoLoadAvg = errors.New("uptime: load average is not available on Windows")
+
+type windowsSource struct{}
+
+// New returns the Source for the current platform.
+func New() Source {
+	return windowsSource{}
+}
+
+func (windowsSource) Uptime() (time.Duration, error) {
+	ms, _, _ := procGetTickCount64.Call()
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (s windowsSource) BootTime() (time.Time, error) {
+	up, err := s.Uptime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-up), nil
+}
+
+func (windowsSource) LoadAvg() ([3]float64, error) {
+	return [3]float64{}, errNoLoadAvg
+}
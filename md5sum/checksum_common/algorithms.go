@@ -0,0 +1,85 @@
+/*
+    go checksum common
+
+    Copyright (c) 2014-2015 Dingjun Fang
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License version 3 as
+	published by the Free Software Foundation.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package checksum_common
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+   algorithm is one entry in the algorithm registry: how to construct a
+   fresh hash.Hash for it, and the name it's tagged with in BSD-style
+   "ALGO (FILENAME) = HASH" output.
+*/
+type algorithm struct {
+	tag string
+	new func() hash.Hash
+}
+
+/*
+   algorithms maps the t string callers already pass to calc_checksum,
+   GenerateChecksum, and CompareChecksum to its algorithm. New algorithms
+   can be added with RegisterAlgorithm without touching the check loop.
+*/
+var algorithms = map[string]algorithm{
+	"md5":      {"MD5", md5.New},
+	"sha1":     {"SHA1", sha1.New},
+	"sha224":   {"SHA224", sha256.New224},
+	"sha256":   {"SHA256", sha256.New},
+	"sha384":   {"SHA384", sha512.New384},
+	"sha512":   {"SHA512", sha512.New},
+	"b2sum":    {"BLAKE2b-512", mustBlake2b(64)},
+	"b2sum384": {"BLAKE2b-384", mustBlake2b(48)},
+	"b2sum256": {"BLAKE2b-256", mustBlake2b(32)},
+	"b2sum160": {"BLAKE2b-160", mustBlake2b(20)},
+	"sha3-256": {"SHA3-256", sha3.New256},
+	"sha3-512": {"SHA3-512", sha3.New512},
+}
+
+/*
+   RegisterAlgorithm adds name to the algorithm registry, making it usable
+   anywhere t is accepted -- calc_checksum, GenerateChecksum,
+   CompareChecksum -- without any change to the check loop. tag is the
+   name used in, and expected back from, BSD-tagged output.
+*/
+func RegisterAlgorithm(name, tag string, newHash func() hash.Hash) {
+	algorithms[name] = algorithm{tag: tag, new: newHash}
+}
+
+/*
+   mustBlake2b returns a hash.Hash constructor for an unkeyed BLAKE2b hash
+   truncated to size bytes. blake2b.New only errors on an out-of-range
+   size or a too-long key, neither of which can happen here.
+*/
+func mustBlake2b(size int) func() hash.Hash {
+	return func() hash.Hash {
+		h, err := blake2b.New(size, nil)
+		if err != nil {
+			panic(err)
+		}
+		return h
+	}
+}
@@ -19,13 +19,8 @@
 package checksum_common
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
 	"fmt"
 	//flag "github.com/ogier/pflag"
-	"hash"
 	"io"
 	"os"
 	"path/filepath"
@@ -35,24 +30,12 @@ import (
    read from os.File and return the whole file's checksum
 */
 func calc_checksum(fp io.Reader, t string) string {
-	var m hash.Hash
-	switch t {
-	case "md5":
-		m = md5.New()
-	case "sha1":
-		m = sha1.New()
-	case "sha512":
-		m = sha512.New()
-	case "sha256":
-		m = sha256.New()
-	case "sha224":
-		m = sha256.New224()
-	case "sha384":
-		m = sha512.New384()
-	default:
+	algo, ok := algorithms[t]
+	if !ok {
 		output_e("unknown type: %s\n", t)
 		return ""
 	}
+	m := algo.new()
 
 	/*  issue:
 	    if fp is os.Stdin, there is no way to trigger EOF
@@ -67,10 +50,28 @@ func calc_checksum(fp io.Reader, t string) string {
 	return fmt.Sprintf("%x", m.Sum(nil))
 }
 
+/*
+   formatChecksumLine renders sum for f in either the legacy GNU form
+   ("SUM *FILE") or, when tag is set, the BSD form ("ALGO (FILE) = SUM").
+   zero terminates the line with NUL instead of newline; GNU coreutils
+   uses this to let filenames contain a newline without escaping, since
+   NUL can't appear in a filename.
+*/
+func formatChecksumLine(t, f, sum string, tag, zero bool) string {
+	term := "\n"
+	if zero {
+		term = "\x00"
+	}
+	if tag {
+		return fmt.Sprintf("%s (%s) = %s%s", algorithms[t].tag, f, sum, term)
+	}
+	return fmt.Sprintf("%s *%s%s", sum, f, term)
+}
+
 /*
    generate the checksum for all of files from cmdline
 */
-func gen_checksum(files []string, t string) bool {
+func gen_checksum(files []string, t string, cfg Config) bool {
 
 	has_error := false
 
@@ -81,7 +82,7 @@ func gen_checksum(files []string, t string) bool {
 		if fn == "-" {
 			sum := calc_checksum(os.Stdin, t)
 			if sum != "" {
-				fmt.Fprintf(os.Stdout, "%s *%s\n", sum, fn)
+				fmt.Fprint(os.Stdout, formatChecksumLine(t, fn, sum, cfg.Tag, cfg.Zero))
 			} else {
 				has_error = true
 			}
@@ -106,7 +107,7 @@ func gen_checksum(files []string, t string) bool {
 			sum := calc_checksum(file, t)
 			file.Close()
 			if sum != "" {
-				fmt.Fprintf(os.Stdout, "%s *%s\n", sum, f)
+				fmt.Fprint(os.Stdout, formatChecksumLine(t, f, sum, cfg.Tag, cfg.Zero))
 			} else {
 				has_error = true
 			}
@@ -123,10 +124,14 @@ func gen_checksum(files []string, t string) bool {
 
    t: the type of checksum, md5 or sha1...
 
+   cfg.Tag selects BSD-tagged output ("ALGO (FILE) = SUM") over the
+   legacy GNU form ("SUM *FILE"); cfg.Zero terminates each line with
+   NUL instead of newline.
+
    return false if there are some errors.
 
    return true if there is no error.
 */
-func GenerateChecksum(files []string, t string) bool {
-	return gen_checksum(files, t)
+func GenerateChecksum(files []string, t string, cfg Config) bool {
+	return gen_checksum(files, t, cfg)
 }
@@ -23,17 +23,66 @@ package checksum_common
 
 import (
 	"bufio"
+	"bytes"
 	//flag "github.com/ogier/pflag"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+/*
+   scanNUL is a bufio.SplitFunc that delimits records on a NUL byte
+   instead of a newline, for reading --zero-generated checksum lists.
+*/
+func scanNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+/*
+   parseChecksumLine parses ll (already trimmed and de-escaped of a
+   leading '\'), accepting either the legacy GNU form ("SUM *FILE"/
+   "SUM  FILE") or the BSD form ("ALGO (FILE) = SUM"). A BSD-form line
+   whose algorithm doesn't match tag is rejected the same as a malformed
+   line, since check_checksum_f only ever verifies against one algorithm.
+*/
+func parseChecksumLine(ll, tag string) (sum, fn string, ok bool) {
+	if algo, rest, found := strings.Cut(ll, " ("); found {
+		end := strings.LastIndex(rest, ") = ")
+		if end < 0 || !strings.EqualFold(algo, tag) {
+			return "", "", false
+		}
+		return rest[end+4:], rest[:end], true
+	}
+
+	fields := strings.Fields(ll)
+	if len(fields) != 2 {
+		return "", "", false
+	}
+	sum, fn = fields[0], fields[1]
+	/* strip the '*' from filename */
+	if fn[0] == '*' {
+		fn = fn[1:]
+	}
+	return sum, fn, true
+}
+
 /*
    check the checksum for all of files
 */
-func check_checksum(files []string, t string) bool {
+func check_checksum(files []string, t string, cfg Config) bool {
 
 	has_err := false
 
@@ -41,7 +90,7 @@ func check_checksum(files []string, t string) bool {
 
 		/* stdin */
 		if files[i] == "-" {
-			if b := check_checksum_f(os.Stdin, t); !b {
+			if b := check_checksum_f(os.Stdin, t, cfg); !b {
 				has_err = true
 			}
 			continue
@@ -54,7 +103,7 @@ func check_checksum(files []string, t string) bool {
 			has_err = true
 			continue
 		}
-		if b := check_checksum_f(file, t); !b {
+		if b := check_checksum_f(file, t, cfg); !b {
 			has_err = true
 		}
 		file.Close()
@@ -66,9 +115,12 @@ func check_checksum(files []string, t string) bool {
 /*
    process single checksum list file
 */
-func check_checksum_f(fp io.Reader, t string) bool {
+func check_checksum_f(fp io.Reader, t string, cfg Config) bool {
 	has_err := false
-	reader := bufio.NewReader(fp)
+	scanner := bufio.NewScanner(fp)
+	if cfg.Zero {
+		scanner.Split(scanNUL)
+	}
 
 	/* total file */
 	total := 0
@@ -82,18 +134,10 @@ func check_checksum_f(fp io.Reader, t string) bool {
 	/* line number */
 	line_num := 0
 
-	for {
+	for scanner.Scan() {
 		line_num += 1
-		l, _, err := reader.ReadLine()
-		if err != nil {
-			if err != io.EOF {
-				has_err = true
-				output_e("%ssum: %s\n", t, err.Error())
-			}
-			break
-		}
 
-		ll := strings.TrimSpace(string(l))
+		ll := strings.TrimSpace(string(scanner.Bytes()))
 
 		if ll == "" {
 			continue
@@ -104,27 +148,25 @@ func check_checksum_f(fp io.Reader, t string) bool {
 			ll = ll[1:]
 		}
 
-		fields := strings.Fields(ll)
-
-		if len(fields) != 2 {
-			if show_warn {
+		sum, fn, ok := parseChecksumLine(ll, algorithms[t].tag)
+		if !ok {
+			if cfg.Warn {
 				output_e("%ssum: line: %d: improperly formatted %s checksum line\n",
 					t, line_num, strings.ToUpper(t))
 			}
+			if cfg.Strict {
+				has_err = true
+			}
 			continue
 		}
 
-		sum, fn := fields[0], fields[1]
-
-		/* strip the '*' from filename */
-		if fn[0] == '*' {
-			fn = fn[1:]
-		}
-
 		fn = filepath.Clean(fn)
 
 		file, err := os.Open(fn)
 		if err != nil {
+			if cfg.IgnoreMissing && os.IsNotExist(err) {
+				continue
+			}
 			output_e("%ssum: %s\n", t, err.Error())
 			has_err = true
 			errored += 1
@@ -141,7 +183,7 @@ func check_checksum_f(fp io.Reader, t string) bool {
 				failed += 1
 				output_e("%s: FAILED\n", fn)
 				has_err = true
-			} else { // success
+			} else if !cfg.Quiet { // success
 				output_n("%s: OK\n", fn)
 			}
 		} else { // error
@@ -150,12 +192,17 @@ func check_checksum_f(fp io.Reader, t string) bool {
 		}
 	}
 
-	if failed > 0 && show_warn {
+	if err := scanner.Err(); err != nil {
+		has_err = true
+		output_e("%ssum: %s\n", t, err.Error())
+	}
+
+	if failed > 0 && cfg.Warn {
 		output_e("%ssum: WARNING: %d of %d computed checksums did NOT match\n",
 			t, failed, total)
 	}
 
-	if errored > 0 && show_warn {
+	if errored > 0 && cfg.Warn {
 		output_e("%ssum: WARNING: %d of %d listed files could not be read\n",
 			t, errored, total)
 	}
@@ -170,12 +217,196 @@ files: file name lists which contains the checksums.
 
 t: the type of checksum, md5 or sha1...
 
+cfg.Status suppresses all output, matching the old no_output behavior;
+cfg.Warn matches the old show_warn behavior. cfg.Quiet, cfg.IgnoreMissing,
+and cfg.Strict add the GNU --quiet/--ignore-missing/--strict semantics on
+top.
+
 return true if everything is ok
 
 return false if there are some errors.
 */
-func CompareChecksum(files []string, t string, output_message, output_warn bool) bool {
-	no_output = !output_message
-	show_warn = output_warn
-	return check_checksum(files, t)
+func CompareChecksum(files []string, t string, cfg Config) bool {
+	no_output = cfg.Status
+	show_warn = cfg.Warn
+	return check_checksum(files, t, cfg)
+}
+
+/*
+   check_checksum_parallel is the concurrent counterpart to
+   check_checksum: each listing file is still opened and scanned in
+   order, but line_checker fans the per-line open+hash work for that
+   file out to workers goroutines (runtime.NumCPU() when workers <= 0).
+*/
+func check_checksum_parallel(files []string, t string, cfg Config, workers int) bool {
+	has_err := false
+
+	for i := 0; i < len(files); i++ {
+		if files[i] == "-" {
+			if b := check_checksum_f_parallel(os.Stdin, t, cfg, workers); !b {
+				has_err = true
+			}
+			continue
+		}
+
+		file, err := os.Open(files[i])
+		if err != nil {
+			output_e("%ssum: %s\n", t, err.Error())
+			has_err = true
+			continue
+		}
+		if b := check_checksum_f_parallel(file, t, cfg, workers); !b {
+			has_err = true
+		}
+		file.Close()
+	}
+
+	return !has_err
+}
+
+/*
+   check_checksum_f_parallel scans fp the same way check_checksum_f
+   does, one line at a time so malformed-line numbers match the serial
+   path exactly, but defers each well-formed line's file open and hash
+   to a bounded pool of workers goroutines. Results are written back
+   into a slice indexed by line order, so the OK/FAILED output below
+   comes out in the same order check_checksum_f would print it,
+   regardless of which worker finishes first. total/failed/errored are
+   shared across workers, so they're updated with atomics.
+*/
+func check_checksum_f_parallel(fp io.Reader, t string, cfg Config, workers int) bool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type job struct {
+		sum, fn string
+	}
+
+	scanner := bufio.NewScanner(fp)
+	if cfg.Zero {
+		scanner.Split(scanNUL)
+	}
+
+	var jobs []job
+	has_err := false
+	line_num := 0
+	for scanner.Scan() {
+		line_num += 1
+
+		ll := strings.TrimSpace(string(scanner.Bytes()))
+		if ll == "" {
+			continue
+		}
+
+		if ll[0] == '\\' {
+			ll = ll[1:]
+		}
+
+		sum, fn, ok := parseChecksumLine(ll, algorithms[t].tag)
+		if !ok {
+			if cfg.Warn {
+				output_e("%ssum: line: %d: improperly formatted %s checksum line\n",
+					t, line_num, strings.ToUpper(t))
+			}
+			if cfg.Strict {
+				has_err = true
+			}
+			continue
+		}
+
+		jobs = append(jobs, job{sum: sum, fn: filepath.Clean(fn)})
+	}
+	if err := scanner.Err(); err != nil {
+		has_err = true
+		output_e("%ssum: %s\n", t, err.Error())
+	}
+
+	const (
+		skipped = iota
+		matched
+		mismatched
+		unreadable
+	)
+	outcomes := make([]int, len(jobs))
+
+	var total, failed, errored int64
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			file, err := os.Open(j.fn)
+			if err != nil {
+				if cfg.IgnoreMissing && os.IsNotExist(err) {
+					outcomes[i] = skipped
+					return
+				}
+				output_e("%ssum: %s\n", t, err.Error())
+				atomic.AddInt64(&errored, 1)
+				outcomes[i] = unreadable
+				return
+			}
+
+			sum1 := calc_checksum(file, t)
+			file.Close()
+
+			atomic.AddInt64(&total, 1)
+			if sum1 == "" {
+				atomic.AddInt64(&errored, 1)
+				outcomes[i] = unreadable
+				return
+			}
+			if sum1 != j.sum {
+				atomic.AddInt64(&failed, 1)
+				outcomes[i] = mismatched
+				return
+			}
+			outcomes[i] = matched
+		}(i, j)
+	}
+	wg.Wait()
+
+	for i, o := range outcomes {
+		switch o {
+		case mismatched:
+			output_e("%s: FAILED\n", jobs[i].fn)
+			has_err = true
+		case matched:
+			if !cfg.Quiet {
+				output_n("%s: OK\n", jobs[i].fn)
+			}
+		case unreadable:
+			has_err = true
+		}
+	}
+
+	if failed > 0 && cfg.Warn {
+		output_e("%ssum: WARNING: %d of %d computed checksums did NOT match\n",
+			t, failed, total)
+	}
+
+	if errored > 0 && cfg.Warn {
+		output_e("%ssum: WARNING: %d of %d listed files could not be read\n",
+			t, errored, total)
+	}
+
+	return !has_err
+}
+
+/*
+   CompareChecksumParallel is the concurrent counterpart to
+   CompareChecksum, parallelizing the per-line file open and hash work
+   within each listing file across workers goroutines (runtime.NumCPU()
+   when workers <= 0).
+*/
+func CompareChecksumParallel(files []string, t string, cfg Config, workers int) bool {
+	no_output = cfg.Status
+	show_warn = cfg.Warn
+	return check_checksum_parallel(files, t, cfg, workers)
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -41,6 +42,26 @@ func TestCalc_checksum(t *testing.T) {
 		{"hello, world", "8710339dcb6814d0d9d2290ef422285c9322b7163951f9a0ca8f883d3305286f44139aa374848e4174f5aada663027e4548637b6d19894aec4fb6c46a139fbf9", "sha512"},
 		{"ad3344412123123fasdfasdf", "e08719391e0e3592db97bf24084ea5230f645da3cb5747aa10e504feafc53426348a61ea9b392be255ac89c28a2ed9092d433b377292827a65a897a2a7687a07", "sha512"},
 		{"333dddf213sfasdfasdfasfd\n", "c7fb59d56d18f86c6838a3a504dcc939e11eec832338f5ef998f222f7cd66527536499dcfac5b8649381adf7665e0557e3574febca7d605a0798b8f737d05f54", "sha512"},
+
+		/* b2sum (BLAKE2b-512) */
+		{"hello, world", "7355dd5276c21cfe0c593b5063b96af3f96a454b33216f58314f44c3ade92e9cd6cec4210a0836246780e9baf927cc50b9a3d7073e8f9bd12780fddbcb930c6d", "b2sum"},
+		{"ad3344412123123fasdfasdf", "7dc2a693c1b6d6381c1105c03fc075420b09acc9d5016453bbc08b5ce57e05a6fefb877368bdf19c6cec1d3b9774c41438428a3f7eb75a81992c0a116645f7be", "b2sum"},
+
+		/* b2sum384 (BLAKE2b-384) */
+		{"hello, world", "33afcbed055720dc6d9f9b9b885f191138a9472b01501dd46177029a6ebd11b55f7c111221772b784b7e97b2998b920f", "b2sum384"},
+
+		/* b2sum256 (BLAKE2b-256) */
+		{"hello, world", "62fbf5098db33f5ee72f85b23b3751d39a2d8d8363f1c734bbb04e05ad2b3b58", "b2sum256"},
+
+		/* b2sum160 (BLAKE2b-160) */
+		{"hello, world", "331af5c65877089f1fe937b277bc0e51cbfce990", "b2sum160"},
+
+		/* sha3-256 */
+		{"hello, world", "bfb3959527d7a3f2f09def2f6915452d55a8f122df9e164d6f31c7fcf6093e14", "sha3-256"},
+		{"333dddf213sfasdfasdfasfd\n", "80b8104c7471884ec858bb75e672a4222ce41e1dddaeb60808f429912cfed822", "sha3-256"},
+
+		/* sha3-512 */
+		{"hello, world", "2ed3a863a12e2f8ff140aa86232ff3603a7f24af62f0e2ca74672494ade175a9a3de42a351b5019d931a1deae0499609038d9b47268779d76198e1d410d20974", "sha3-512"},
 	}
 
 	for _, v := range cases {
@@ -54,6 +75,95 @@ func TestCalc_checksum(t *testing.T) {
 	}
 }
 
+func TestParseChecksumLine(t *testing.T) {
+	cases := []struct {
+		line, tag       string
+		wantSum, wantFn string
+		wantOk          bool
+	}{
+		{"e4d7f1b4ed2e42d15898f4b27b019da4 *hello.txt", "MD5", "e4d7f1b4ed2e42d15898f4b27b019da4", "hello.txt", true},
+		{"e4d7f1b4ed2e42d15898f4b27b019da4  hello.txt", "MD5", "e4d7f1b4ed2e42d15898f4b27b019da4", "hello.txt", true},
+		{"MD5 (hello.txt) = e4d7f1b4ed2e42d15898f4b27b019da4", "MD5", "e4d7f1b4ed2e42d15898f4b27b019da4", "hello.txt", true},
+		{"md5 (hello.txt) = e4d7f1b4ed2e42d15898f4b27b019da4", "MD5", "e4d7f1b4ed2e42d15898f4b27b019da4", "hello.txt", true},
+		{"SHA256 (hello.txt) = e4d7f1b4ed2e42d15898f4b27b019da4", "MD5", "", "", false},
+		{"not a valid line at all here", "MD5", "", "", false},
+	}
+
+	for _, v := range cases {
+		sum, fn, ok := parseChecksumLine(v.line, v.tag)
+		if ok != v.wantOk || sum != v.wantSum || fn != v.wantFn {
+			t.Errorf("parseChecksumLine(%#v, %#v) = (%#v, %#v, %v), want (%#v, %#v, %v)",
+				v.line, v.tag, sum, fn, ok, v.wantSum, v.wantFn, v.wantOk)
+		}
+	}
+}
+
+func TestFormatChecksumLine(t *testing.T) {
+	const sum = "e4d7f1b4ed2e42d15898f4b27b019da4"
+
+	if got, want := formatChecksumLine("md5", "hello.txt", sum, false, false), sum+" *hello.txt\n"; got != want {
+		t.Errorf("untagged form: got %#v, want %#v", got, want)
+	}
+	if got, want := formatChecksumLine("md5", "hello.txt", sum, true, false), "MD5 (hello.txt) = "+sum+"\n"; got != want {
+		t.Errorf("tagged form: got %#v, want %#v", got, want)
+	}
+	if got, want := formatChecksumLine("md5", "hello.txt", sum, false, true), sum+" *hello.txt\x00"; got != want {
+		t.Errorf("zero-terminated form: got %#v, want %#v", got, want)
+	}
+}
+
+func TestCompareChecksumZeroRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(fn, []byte("zero terminated round trip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := captureStdout(t, func() {
+		if !GenerateChecksum([]string{fn}, "sha256", Config{Zero: true}) {
+			t.Fatal("GenerateChecksum reported failure")
+		}
+	})
+
+	listFn := filepath.Join(dir, "checksum.sha256")
+	if err := os.WriteFile(listFn, []byte(generated), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !CompareChecksum([]string{listFn}, "sha256", Config{Zero: true, Status: true}) {
+		t.Errorf("CompareChecksum rejected a zero-terminated list it just generated")
+	}
+}
+
+func TestCompareChecksumStrictAndIgnoreMissing(t *testing.T) {
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(fn, []byte("strict test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	good := captureStdout(t, func() {
+		GenerateChecksum([]string{fn}, "sha256", Config{})
+	})
+	missing := filepath.Join(dir, "missing.txt")
+
+	listFn := filepath.Join(dir, "checksum.sha256")
+	malformed := good + "not a checksum line\n" + "deadbeef *" + missing + "\n"
+	if err := os.WriteFile(listFn, []byte(malformed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if CompareChecksum([]string{listFn}, "sha256", Config{Status: true}) {
+		t.Error("CompareChecksum should fail on a missing listed file without --ignore-missing")
+	}
+	if CompareChecksum([]string{listFn}, "sha256", Config{Status: true, Strict: true}) {
+		t.Error("CompareChecksum with Strict should fail on the malformed line")
+	}
+	if !CompareChecksum([]string{listFn}, "sha256", Config{Status: true, IgnoreMissing: true}) {
+		t.Error("CompareChecksum with IgnoreMissing should tolerate the missing listed file")
+	}
+}
+
 func TestCheck_checksum(t *testing.T) {
 
 	old_stdout := os.Stdout
@@ -81,7 +191,7 @@ func TestCheck_checksum(t *testing.T) {
 	for _, m := range sum_methods {
 		fn := fmt.Sprintf("testdata/checksum.%s", m)
 		sum_f_lists := []string{fn}
-		if r := CompareChecksum(sum_f_lists, m, true, true); !r {
+		if r := CompareChecksum(sum_f_lists, m, Config{Warn: true}); !r {
 			t.Fail()
 		} else {
 			t.Logf("check %s for %s: success\n", fn, m)
@@ -115,7 +225,7 @@ func TestGenChecksum(t *testing.T) {
 	sum_methods := []string{"md5", "sha1", "sha224", "sha256", "sha384", "sha512"}
 	for _, m := range sum_methods {
 		flists := []string{"testdata/*.txt"}
-		if r := GenerateChecksum(flists, m); !r {
+		if r := GenerateChecksum(flists, m, Config{}); !r {
 			t.Fail()
 		} else {
 			t.Logf("generate %sum: success\n", m)
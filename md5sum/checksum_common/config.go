@@ -0,0 +1,54 @@
+/*
+    go checksum common
+
+    Copyright (c) 2014-2015 Dingjun Fang
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License version 3 as
+	published by the Free Software Foundation.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package checksum_common
+
+/*
+   Config collects the GNU-style options GenerateChecksum and
+   CompareChecksum used to take as separate bool parameters.
+*/
+type Config struct {
+	/* Tag requests BSD-style "ALGO (FILE) = SUM" output from
+	   GenerateChecksum, and makes CompareChecksum expect it, instead of
+	   the legacy GNU "SUM *FILE" form. */
+	Tag bool
+
+	/* Zero terminates each generated line with NUL instead of newline,
+	   and makes CompareChecksum split its input on NUL instead of
+	   newline. Unlike the newline form, it needs no escaping for
+	   filenames that themselves contain a newline. */
+	Zero bool
+
+	/* The rest only affect CompareChecksum. */
+
+	/* Quiet suppresses the "FILE: OK" line printed for each successfully
+	   verified file; FAILED lines and read errors still print. */
+	Quiet bool
+	/* Status suppresses all output; success or failure is conveyed only
+	   through CompareChecksum's return value. */
+	Status bool
+	/* Warn prints a warning for each improperly formatted checksum line,
+	   plus the "WARNING: n of m ..." summaries. */
+	Warn bool
+	/* IgnoreMissing skips, without treating it as a failure, a listed
+	   file that doesn't exist. */
+	IgnoreMissing bool
+	/* Strict makes an improperly formatted checksum line fail the whole
+	   comparison rather than just printing (if Warn) a per-line warning. */
+	Strict bool
+}
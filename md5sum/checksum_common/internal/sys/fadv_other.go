@@ -0,0 +1,16 @@
+// +build !linux,!freebsd
+
+package sys
+
+// Advice values accepted by Fadvise. posix_fadvise(2) has no
+// equivalent outside Linux and FreeBSD, so these are unused by Fadvise
+// below; they exist so callers don't need a build tag of their own.
+const (
+	Sequential = 0
+	DontNeed   = 0
+)
+
+// Fadvise is a no-op on platforms without posix_fadvise(2).
+func Fadvise(fd int, offset, length int64, advice int) error {
+	return nil
+}
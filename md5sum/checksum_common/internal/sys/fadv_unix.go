@@ -0,0 +1,18 @@
+// +build linux freebsd
+
+package sys
+
+import "golang.org/x/sys/unix"
+
+// Advice values accepted by Fadvise, forwarding to the posix_fadvise(2)
+// constants of the same meaning.
+const (
+	Sequential = unix.FADV_SEQUENTIAL
+	DontNeed   = unix.FADV_DONTNEED
+)
+
+// Fadvise advises the kernel on how fd will be accessed over the
+// range [offset, offset+length), or the whole file when length is 0.
+func Fadvise(fd int, offset, length int64, advice int) error {
+	return unix.Fadvise(fd, offset, length, advice)
+}
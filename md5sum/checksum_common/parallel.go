@@ -0,0 +1,151 @@
+/*
+    go checksum common
+
+    Copyright (c) 2014-2015 Dingjun Fang
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License version 3 as
+	published by the Free Software Foundation.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package checksum_common
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/ericlagergren/go-coreutils/md5sum/checksum_common/internal/sys"
+	"golang.org/x/exp/mmap"
+)
+
+/*
+   mmapThreshold is the regular-file size at or above which hashFile
+   hashes the file from a memory mapping instead of from the open
+   *os.File.
+*/
+const mmapThreshold = 64 * 1024
+
+/*
+   GenerateChecksumParallel is the concurrent counterpart to
+   GenerateChecksum. files (which may contain globs, same as
+   GenerateChecksum) is expanded once up front; the resulting file list
+   is then handed to workers goroutines (runtime.NumCPU() when
+   workers <= 0), each hashing one file at a time.
+
+   Output is collected into a slice indexed by job order and printed
+   after every worker finishes, so it comes out in the same order
+   GenerateChecksum would produce it regardless of which worker
+   happens to finish first.
+
+   return false if there are some errors.
+
+   return true if there is no error.
+*/
+func GenerateChecksumParallel(files []string, t string, cfg Config, workers int) bool {
+	if _, ok := algorithms[t]; !ok {
+		output_e("unknown type: %s\n", t)
+		return false
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var jobs []string
+	for _, fn := range files {
+		if fn == "-" {
+			jobs = append(jobs, fn)
+			continue
+		}
+		filenames, _ := filepath.Glob(fn)
+		if filenames == nil {
+			filenames = append(filenames, fn)
+		}
+		jobs = append(jobs, filenames...)
+	}
+
+	type outcome struct {
+		line string
+		ok   bool
+	}
+	outcomes := make([]outcome, len(jobs))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, fn := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, fn string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if sum, ok := hashFile(fn, t); ok {
+				outcomes[i] = outcome{line: formatChecksumLine(t, fn, sum, cfg.Tag, cfg.Zero), ok: true}
+			}
+		}(i, fn)
+	}
+	wg.Wait()
+
+	has_error := false
+	for _, o := range outcomes {
+		if !o.ok {
+			has_error = true
+			continue
+		}
+		fmt.Fprint(os.Stdout, o.line)
+	}
+	return !has_error
+}
+
+/*
+   hashFile computes fn's checksum of type t. Regular files of
+   mmapThreshold bytes or more are mapped into memory with
+   golang.org/x/exp/mmap and hashed through an io.SectionReader over the
+   mapping. calc_checksum's io.Copy still copies the mapped bytes
+   through its own buffer on the way to the hash, so the mapping
+   replaces read(2) calls but not that copy. Smaller files, non-regular
+   files and stdin go through calc_checksum directly.
+*/
+func hashFile(fn, t string) (sum string, ok bool) {
+	if fn == "-" {
+		sum = calc_checksum(os.Stdin, t)
+		return sum, sum != ""
+	}
+
+	file, err := os.Open(fn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%ssum: %s\n", t, err.Error())
+		return "", false
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || !stat.Mode().IsRegular() || stat.Size() < mmapThreshold {
+		sum = calc_checksum(file, t)
+		return sum, sum != ""
+	}
+
+	fd := int(file.Fd())
+	sys.Fadvise(fd, 0, stat.Size(), sys.Sequential)
+	defer sys.Fadvise(fd, 0, stat.Size(), sys.DontNeed)
+
+	r, err := mmap.Open(fn)
+	if err != nil {
+		sum = calc_checksum(file, t)
+		return sum, sum != ""
+	}
+	defer r.Close()
+
+	sum = calc_checksum(io.NewSectionReader(r, 0, int64(r.Len())), t)
+	return sum, sum != ""
+}
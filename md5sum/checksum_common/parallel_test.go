@@ -0,0 +1,170 @@
+package checksum_common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateChecksumParallel(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 16; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+		if err := os.WriteFile(fn, []byte(fmt.Sprintf("contents of file %d\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	want := serialChecksums(t, names, "sha256")
+
+	for _, workers := range []int{0, 1, 4} {
+		got := captureStdout(t, func() {
+			if !GenerateChecksumParallel(names, "sha256", Config{}, workers) {
+				t.Errorf("workers=%d: GenerateChecksumParallel reported failure", workers)
+			}
+		})
+		if got != want {
+			t.Errorf("workers=%d: got\n%s\nwant\n%s", workers, got, want)
+		}
+	}
+}
+
+func TestCompareChecksumParallel(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 16; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("file%02d.txt", i))
+		if err := os.WriteFile(fn, []byte(fmt.Sprintf("contents of file %d\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	listing := serialChecksums(t, names, "sha256")
+	listFn := filepath.Join(dir, "checksum.sha256")
+	if err := os.WriteFile(listFn, []byte(listing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 1, 4} {
+		if !CompareChecksumParallel([]string{listFn}, "sha256", Config{Status: true}, workers) {
+			t.Errorf("workers=%d: CompareChecksumParallel reported failure on a listing it just generated", workers)
+		}
+	}
+
+	if err := os.Remove(names[3]); err != nil {
+		t.Fatal(err)
+	}
+	if CompareChecksumParallel([]string{listFn}, "sha256", Config{Status: true}, 4) {
+		t.Error("CompareChecksumParallel should fail once a listed file goes missing")
+	}
+	if !CompareChecksumParallel([]string{listFn}, "sha256", Config{Status: true, IgnoreMissing: true}, 4) {
+		t.Error("CompareChecksumParallel with IgnoreMissing should tolerate the missing listed file")
+	}
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func serialChecksums(t *testing.T, names []string, algo string) string {
+	t.Helper()
+	var out string
+	for _, fn := range names {
+		f, err := os.Open(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := calc_checksum(f, algo)
+		f.Close()
+		out += formatChecksumLine(algo, fn, sum, false, false)
+	}
+	return out
+}
+
+// BenchmarkGenerateChecksumSerial and BenchmarkGenerateChecksumParallel
+// compare hashing a synthetic tree of 10k small files plus a handful
+// of large ones the old, serial way and through
+// GenerateChecksumParallel.
+func benchTree(b *testing.B) []string {
+	b.Helper()
+	dir := b.TempDir()
+	rng := rand.New(rand.NewSource(1))
+
+	var names []string
+	small := make([]byte, 512)
+	rng.Read(small)
+	for i := 0; i < 10000; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("small%05d.bin", i))
+		if err := os.WriteFile(fn, small, 0644); err != nil {
+			b.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	large := make([]byte, 4*1024*1024)
+	rng.Read(large)
+	for i := 0; i < 4; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("large%d.bin", i))
+		if err := os.WriteFile(fn, large, 0644); err != nil {
+			b.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	return names
+}
+
+func silenceStdout(b *testing.B) func() {
+	old := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	os.Stdout = devNull
+	return func() {
+		devNull.Close()
+		os.Stdout = old
+	}
+}
+
+func BenchmarkGenerateChecksumSerial(b *testing.B) {
+	names := benchTree(b)
+	defer silenceStdout(b)()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateChecksum(names, "sha256", Config{})
+	}
+}
+
+func BenchmarkGenerateChecksumParallel(b *testing.B) {
+	names := benchTree(b)
+	defer silenceStdout(b)()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateChecksumParallel(names, "sha256", Config{}, 0)
+	}
+}
@@ -29,19 +29,26 @@ Print or check MD5 checksums.
 With no FILE, or when FILE is -, read standard input.
   -c, --check   check MD5 sums against given list
 
-The following two options are useful only when verifying checksums:
-      --status   don't output anything, status code shows success
-  -w, --warn     warn about improperly formated checksum lines
-      --help     show help and exit
-      --version  show version and exit
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
+      --status          don't output anything, status code shows success
+      --strict          exit non-zero for improperly formatted lines
+  -w, --warn            warn about improperly formated checksum lines
+
+      --tag       create a BSD-style checksum
+  -z, --zero      end each output line with NUL, not newline
+  -j, --jobs=N    hash N files concurrently (default: run serially)
+      --help      show help and exit
+      --version   show version and exit
 */
 package main
 
 import (
 	"fmt"
-	flag "github.com/ogier/pflag"
 	"os"
-	//"path/filepath"
+
+	cc "github.com/ericlagergren/go-coreutils/md5sum/checksum_common"
+	flag "github.com/ogier/pflag"
 )
 
 const (
@@ -51,11 +58,17 @@ Print or check MD5 checksums.
 With no FILE, or when FILE is -, read standard input.
   -c, --check   check MD5 sums against given list
 
-The following two options are useful only when verifying checksums:
-      --status   don't output anything, status code shows success
-  -w, --warn     warn about improperly formated checksum lines
-      --help     show help and exit
-      --version  show version and exit
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
+      --status          don't output anything, status code shows success
+      --strict          exit non-zero for improperly formatted lines
+  -w, --warn            warn about improperly formated checksum lines
+
+      --tag       create a BSD-style checksum
+  -z, --zero      end each output line with NUL, not newline
+  -j, --jobs=N    hash N files concurrently (default: run serially)
+      --help      show help and exit
+      --version   show version and exit
 `
 	Version = `md5sum (Go coreutils) 0.1
 Copyright (C) 2015 Dingjun Fang
@@ -66,10 +79,15 @@ There is NO WARRANTY, to the extent permitted by law.
 )
 
 var (
-	check_sum    = flag.BoolP("check", "c", false, "")
-	no_output    = flag.BoolP("status", "", false, "")
-	show_warn    = flag.BoolP("warn", "w", true, "")
-	show_version = flag.BoolP("version", "v", false, "")
+	checkSum      = flag.BoolP("check", "c", false, "")
+	status        = flag.BoolP("status", "", false, "")
+	warn          = flag.BoolP("warn", "w", true, "")
+	tag           = flag.BoolP("tag", "", false, "")
+	zero          = flag.BoolP("zero", "z", false, "")
+	ignoreMissing = flag.BoolP("ignore-missing", "", false, "")
+	strict        = flag.BoolP("strict", "", false, "")
+	jobs          = flag.IntP("jobs", "j", 0, "")
+	showVersion   = flag.BoolP("version", "v", false, "")
 )
 
 func main() {
@@ -80,23 +98,38 @@ func main() {
 
 	flag.Parse()
 
-	has_error := false
-
-	switch {
-	case *show_version:
+	if *showVersion {
 		fmt.Fprintf(os.Stdout, "%s", Version)
 		os.Exit(0)
-	case *check_sum:
-		if !check_md5sum() {
-			has_error = true
-		}
+	}
+
+	cfg := cc.Config{
+		Tag:           *tag,
+		Status:        *status,
+		Warn:          *warn,
+		Zero:          *zero,
+		IgnoreMissing: *ignoreMissing,
+		Strict:        *strict,
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var ok bool
+	switch {
+	case *checkSum && *jobs > 0:
+		ok = cc.CompareChecksumParallel(files, "md5", cfg, *jobs)
+	case *checkSum:
+		ok = cc.CompareChecksum(files, "md5", cfg)
+	case *jobs > 0:
+		ok = cc.GenerateChecksumParallel(files, "md5", cfg, *jobs)
 	default:
-		if !gen_md5sum() {
-			has_error = true
-		}
+		ok = cc.GenerateChecksum(files, "md5", cfg)
 	}
 
-	if has_error {
+	if !ok {
 		os.Exit(1)
 	}
 
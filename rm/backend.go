@@ -0,0 +1,47 @@
+package rm
+
+import (
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// Backend performs the actual removal of a single file or directory that rm
+// has already decided to remove (permission checks, prompting, and -r
+// traversal all happen above this layer). During -r traversal RemoveDir is
+// called on a directory only once its entries are gone; with a
+// TrashBackend, a directory named without -r is handed to RemoveDir with
+// its contents still in place, to be moved as a whole.
+type Backend interface {
+	RemoveFile(path string, info os.FileInfo) error
+	RemoveDir(path string, info os.FileInfo) error
+}
+
+// UnlinkBackend is the default Backend: it unlinks/rmdirs the target
+// in place.
+type UnlinkBackend struct{}
+
+func (UnlinkBackend) RemoveFile(path string, _ os.FileInfo) error { return unlinkOrRemove(path, false) }
+func (UnlinkBackend) RemoveDir(path string, _ os.FileInfo) error  { return unlinkOrRemove(path, true) }
+
+// unlinkOrRemove removes name, calling unix.Unlink/unix.Rmdir directly on
+// platforms that have them (since the caller already knows the object's
+// type, unlike os.Remove) and falling back to os.Remove elsewhere.
+func unlinkOrRemove(name string, dir bool) error {
+	switch runtime.GOOS {
+	case "windows", "plan9":
+		return os.Remove(name)
+	default:
+		var err error
+		if dir {
+			err = unix.Rmdir(name)
+		} else {
+			err = unix.Unlink(name)
+		}
+		if err != nil {
+			return &os.PathError{Op: "remove", Path: name, Err: err}
+		}
+		return nil
+	}
+}
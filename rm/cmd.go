@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
 
 	coreutils "github.com/ericlagergren/go-coreutils"
 	flag "github.com/spf13/pflag"
@@ -37,6 +38,7 @@ func newCommand() *cmd {
 	c.f.BoolVarP(&c.rmdir, "dir", "d", false, "remove empty directories")
 	c.f.BoolVarP(&c.verbose, "verbose", "v", false, "explain what's occurring")
 	c.f.BoolVar(&c.version, "version", false, "print version information and exit")
+	c.f.IntVarP(&c.jobs, "jobs", "j", runtime.NumCPU(), "remove up to N files concurrently when recursing (ignored with -i or --interactive=always)")
 	return &c
 }
 
@@ -52,6 +54,7 @@ type cmd struct {
 	rmdir                bool
 	verbose              bool
 	version              bool
+	jobs                 int
 }
 
 func run(ctx coreutils.Context, args ...string) error {
@@ -122,6 +125,7 @@ func run(ctx coreutils.Context, args ...string) error {
 	}
 
 	r := NewRemover(opts)
+	r.Concurrency = c.jobs
 
 	if r.opts&PromptAlways != 0 {
 		r.Prompt = func(name string, opts PromptOption) bool {
@@ -0,0 +1,65 @@
+package rm
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/ericlagergren/go-coreutils/rm/internal/sys"
+)
+
+// RemoveFS is the filesystem abstraction Remover's traversal and removal
+// calls go through. The zero Remover uses OSFS, but satisfying RemoveFS
+// with something else -- an in-memory tree, an archive, an overlay -- lets
+// rm run against it unchanged, the way containerd's continuity/fs package
+// lets its callers operate on more than the real disk.
+type RemoveFS interface {
+	fs.ReadDirFS
+	fs.StatFS
+
+	// Remove and RemoveDir perform the actual removal of a single file or
+	// already-empty directory. They play the role OSFS's fast-path
+	// Unlink/Rmdir split plays for the real filesystem.
+	Remove(name string) error
+	RemoveDir(name string) error
+
+	// SameFile reports whether a and b are the same object, the way
+	// os.SameFile does for the real filesystem; it backs the
+	// NoPreserveRoot check.
+	SameFile(a, b fs.FileInfo) bool
+	// SameDevice reports whether a and b live on the same device/volume;
+	// it backs the OneFileSystem check.
+	SameDevice(a, b fs.FileInfo) bool
+}
+
+// NewRemoverFS is NewRemover, but against fsys instead of the real
+// filesystem.
+func NewRemoverFS(fsys RemoveFS, opts RemoveOption) *Remover {
+	r := NewRemover(opts)
+	r.FS = fsys
+	return r
+}
+
+// fsys returns r.FS, defaulting to OSFS when unset.
+func (r *Remover) fsys() RemoveFS {
+	if r.FS == nil {
+		return OSFS{}
+	}
+	return r.FS
+}
+
+// OSFS is the default RemoveFS: the real filesystem, addressed by the same
+// paths Remover has always accepted.
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFS) Stat(name string) (fs.FileInfo, error)      { return os.Lstat(name) }
+func (OSFS) SameFile(a, b fs.FileInfo) bool             { return os.SameFile(a, b) }
+func (OSFS) SameDevice(a, b fs.FileInfo) bool           { return !sys.DiffFS(a, b) }
+
+// Remove and RemoveDir preserve the original fast path, also used directly
+// by UnlinkBackend: unix.Unlink/unix.Rmdir on platforms that have them,
+// since the caller already knows the object's type (unlike os.Remove),
+// falling back to os.Remove elsewhere.
+func (OSFS) Remove(name string) error    { return unlinkOrRemove(name, false) }
+func (OSFS) RemoveDir(name string) error { return unlinkOrRemove(name, true) }
@@ -23,3 +23,23 @@ func IsRoot(info os.FileInfo) bool {
 func DiffFS(orig, test os.FileInfo) bool {
 	return orig.Sys().(*syscall.Stat_t).Dev != test.Sys().(*syscall.Stat_t).Dev
 }
+
+// cowMagics lists filesystem magic numbers (statfs(2)'s f_type) that are
+// copy-on-write or log-structured, where overwriting a file in place isn't
+// guaranteed to touch the blocks its old data actually occupies.
+var cowMagics = map[int64]bool{
+	0x9123683e: true, // btrfs
+	0x2fc12fc1: true, // zfs
+	0xf2f52010: true, // f2fs
+	0x01021994: true, // tmpfs
+}
+
+// IsCOWFS reports whether path lives on a copy-on-write or log-structured
+// filesystem.
+func IsCOWFS(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, err
+	}
+	return cowMagics[int64(st.Type)], nil
+}
@@ -0,0 +1,79 @@
+package rm
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// MemFS is an in-memory RemoveFS built on testing/fstest.MapFS, for tests
+// that exercise Remover without touching the real disk. It has a single
+// virtual device, so SameDevice is always true and SameFile -- which only
+// backs the NoPreserveRoot check, meaningless for a tree with no real
+// root -- is always false.
+type MemFS struct {
+	fstest.MapFS
+}
+
+func (m MemFS) Remove(name string) error {
+	if _, ok := m.MapFS[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.MapFS, name)
+	return nil
+}
+
+// RemoveDir deletes name's map entry, if it has one. Directories that only
+// exist implicitly, as a prefix of some file's path, have none; once their
+// last child is gone MapFS stops synthesizing them, so there's nothing
+// left to do.
+func (m MemFS) RemoveDir(name string) error {
+	delete(m.MapFS, name)
+	return nil
+}
+
+func (MemFS) SameFile(a, b fs.FileInfo) bool   { return false }
+func (MemFS) SameDevice(a, b fs.FileInfo) bool { return true }
+
+func TestMemFSRemoveFile(t *testing.T) {
+	fsys := MemFS{fstest.MapFS{
+		"dir/a.txt": {Data: []byte("hi")},
+	}}
+
+	r := NewRemoverFS(fsys, 0)
+	if err := r.Remove("dir/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fsys.MapFS["dir/a.txt"]; ok {
+		t.Fatal("dir/a.txt still present")
+	}
+}
+
+func TestMemFSRemoveRecursive(t *testing.T) {
+	fsys := MemFS{fstest.MapFS{
+		"dir/a.txt":     {Data: []byte("hi")},
+		"dir/sub/b.txt": {Data: []byte("bye")},
+	}}
+
+	r := NewRemoverFS(fsys, Recursive)
+	if err := r.Remove("dir"); err != nil {
+		t.Fatal(err)
+	}
+	for name := range fsys.MapFS {
+		t.Errorf("unexpected leftover entry %q", name)
+	}
+}
+
+func TestMemFSRemoveNonEmptyRefused(t *testing.T) {
+	fsys := MemFS{fstest.MapFS{
+		"dir/a.txt": {Data: []byte("hi")},
+	}}
+
+	r := NewRemoverFS(fsys, 0)
+	if err := r.Remove("dir"); err == nil {
+		t.Fatal("expected an error removing a non-empty directory without Recursive")
+	}
+	if _, ok := fsys.MapFS["dir/a.txt"]; !ok {
+		t.Fatal("dir/a.txt should not have been removed")
+	}
+}
@@ -0,0 +1,128 @@
+package rm
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// pnode tracks how many of a directory's immediate children are still
+// outstanding during parallel removal. Whichever worker goroutine finishes
+// the last child is responsible for removing the directory itself and
+// cascading the same check to its parent, so the parent-after-children
+// invariant holds without any cross-goroutine coordination beyond this
+// counter.
+type pnode struct {
+	path    string
+	info    os.FileInfo
+	parent  *pnode
+	pending int32
+}
+
+// job is a single file queued for removal by removeParallel's worker pool.
+type job struct {
+	path string
+	info os.FileInfo
+	node *pnode
+}
+
+// removeParallel mirrors walk's DFS traversal and remove-parent-after-children
+// invariant, but hands the actual file removals to a pool of r.Concurrency
+// worker goroutines instead of doing them inline. Traversal (including
+// prompting) stays on a single dispatch goroutine so descend order is
+// deterministic; it's also responsible for removing and cascading empty
+// directories, since no worker ever "finishes a child" of one of those.
+func (r *Remover) removeParallel(path string, info os.FileInfo) error {
+	jobs := make(chan job, r.Concurrency)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		if err == nil || err == errRefused {
+			return
+		}
+		errOnce.Do(func() { firstErr = err })
+	}
+
+	wg.Add(r.Concurrency)
+	for i := 0; i < r.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if err := r.remove(j.path, j.info); err != nil {
+					fail(err)
+					continue
+				}
+				fail(r.childDone(j.node))
+			}
+		}()
+	}
+
+	fail(r.dispatch(path, info, nil, jobs))
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
+
+// childDone records that one of n's children has been removed, completing
+// (and cascading) n once every child it had is gone.
+func (r *Remover) childDone(n *pnode) error {
+	if atomic.AddInt32(&n.pending, -1) != 0 {
+		return nil
+	}
+	return r.completeDir(n)
+}
+
+// completeDir removes n, which by this point has no outstanding children,
+// and, if n has a parent, records n itself as one of that parent's
+// completed children -- the cascade that lets a directory be Rmdir'd as
+// soon as, but only once, every child it had is gone.
+func (r *Remover) completeDir(n *pnode) error {
+	if err := r.remove(n.path, n.info); err != nil && err != errRefused {
+		return err
+	}
+	if n.parent == nil {
+		return nil
+	}
+	return r.childDone(n.parent)
+}
+
+// dispatch walks the tree under path, queueing file removals onto jobs for
+// the worker pool and recursing into subdirectories directly. Empty
+// directories have no child to trigger their own cascade, so dispatch
+// finishes them inline instead.
+func (r *Remover) dispatch(path string, info os.FileInfo, parent *pnode, jobs chan<- job) error {
+	if !r.prompt(path, Descend) {
+		return nil
+	}
+	entries, err := r.readDir(path)
+	if err != nil {
+		return err
+	}
+
+	n := &pnode{path: path, info: info, parent: parent, pending: int32(len(entries))}
+	if len(entries) == 0 {
+		return r.completeDir(n)
+	}
+
+	for _, ent := range entries {
+		child := filepath.Join(path, ent.Name())
+		childInfo, err := ent.Info()
+		if err != nil {
+			return err
+		}
+		if childInfo.IsDir() {
+			if err := r.dispatch(child, childInfo, n, jobs); err != nil {
+				return err
+			}
+			continue
+		}
+		jobs <- job{path: child, info: childInfo, node: n}
+	}
+	return nil
+}
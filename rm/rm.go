@@ -5,13 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
-
-	"github.com/ericlagergren/go-coreutils/rm/internal/sys"
-	"golang.org/x/sys/unix"
 )
 
-type RemoveOption uint8
+type RemoveOption uint16
 
 const (
 	NoPreserveRoot = 1 << iota
@@ -22,6 +18,9 @@ const (
 	OneFileSystem
 	Verbose
 	PromptAlways
+	// Shred overwrites a regular file's contents in place (per the
+	// Remover's Shred ShredConfig) before unlinking it. See shred.go.
+	Shred
 )
 
 type PromptOption uint8
@@ -56,17 +55,44 @@ type Remover struct {
 
 	Log chan string
 
+	// Concurrency, if greater than 1, parallelizes recursive removal across
+	// that many worker goroutines (see removeParallel). The default, 0,
+	// keeps the original single-goroutine traversal.
+	Concurrency int
+
+	// ShredCfg configures the overwrite passes performed when the Shred
+	// option is set. See shred.go.
+	ShredCfg ShredConfig
+
+	// OnError, if non-nil, is consulted when shredding a file fails,
+	// whether with ErrShredUnsupported or any other error. Returning true
+	// tells the Remover to proceed anyway (falling back to a plain
+	// unlink); returning false aborts with that error.
+	OnError func(error) bool
+
+	// Backend performs the actual removal of each file/directory rm
+	// decides to remove. It defaults to UnlinkBackend; set it to a
+	// TrashBackend to move removed files into the user's trash instead.
+	Backend Backend
+
+	// FS is the filesystem Remover traverses and removes from. It defaults
+	// to OSFS, the real filesystem; set it to something else (see
+	// RemoveFS) to run against a virtual filesystem instead, or use
+	// NewRemoverFS.
+	FS RemoveFS
+
 	stack []node
 }
 
 type node struct {
-	path string
-	info os.FileInfo
-	kids int
+	path    string
+	info    os.FileInfo
+	entries []os.DirEntry
+	idx     int
 }
 
 func (r *Remover) Remove(path string) (err error) {
-	r.root, err = os.Lstat(path)
+	r.root, err = r.fsys().Stat(path)
 	if err != nil {
 		return err
 	}
@@ -78,73 +104,91 @@ func (r *Remover) Remove(path string) (err error) {
 		return nil
 	}
 
-	// GNU rm uses a DFS that, once it reaches a leaf node (doesn't contain any
-	// further directories), clears out all files and "walks back" to the most
-	// recently seen non-leaf node. This is typicall DFS behavior, but the
-	// walking back is important: it allows the prompt for interactive usage to
-	// look like this:
-	//
-	//  $ mkdir a/b/c
-	//  $ touch a/b/c/d.txt
-	//  $ rm a/
-	//  rm: descend into 'a'?
-	//  rm: descend into 'a/b'?
-	//  rm: descend into 'a/b/c'?
-	//  rm: remove file 'a/b/c/d.txt'?
-	//  rm: remove directory 'a/b/c'?
-	//  rm: remove directory 'a/b'?
-	//  rm: remove directory 'a'?
-	//
-	// Unfortunately, filepath.Walk doesn't allow us to walk back, so we're
-	// forced to do a little state management ourselves. We push each directory
-	// we encounter onto a stack. Once we hit a leaf node, we manually work our
-	// way back by popping every consecutive leaf node off the stack, removing
-	// it as we go. Since filepath.Walk doesn't work backwards, this works.
-	//
-	// A major downside is the requirement of determining how many objects are
-	// in a directory. This means Stat will be called twice for each directory:
-	// once for filepath.Walk, once for us. Same goes for Readdirnames.
-	return filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	// Prompts read yes/no answers off a single stdin; running the prompt
+	// callback from multiple worker goroutines at once would race those
+	// reads and could hand the wrong answer to the wrong file. Fall back
+	// to the single-goroutine walk whenever prompting is enabled.
+	if r.Concurrency > 1 && r.opts&PromptAlways == 0 {
+		return r.removeParallel(path, r.root)
+	}
+	return r.walk(path, r.root)
+}
 
-		if info.IsDir() {
-			if !r.prompt(path, Descend) {
-				return filepath.SkipDir
-			}
-			dir, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			files, err := dir.Readdirnames(-1)
-			if err != nil {
+// walk performs GNU rm's DFS: once it reaches a leaf node (a directory
+// containing no further directories), it clears out all files and "walks
+// back" to the most recently seen non-leaf node. This is typical DFS
+// behavior, but the walking back is important: it allows the prompt for
+// interactive usage to look like this:
+//
+//	$ mkdir a/b/c
+//	$ touch a/b/c/d.txt
+//	$ rm a/
+//	rm: descend into 'a'?
+//	rm: descend into 'a/b'?
+//	rm: descend into 'a/b/c'?
+//	rm: remove file 'a/b/c/d.txt'?
+//	rm: remove directory 'a/b/c'?
+//	rm: remove directory 'a/b'?
+//	rm: remove directory 'a'?
+//
+// Each directory is pushed onto r.stack along with the entries read for it
+// (a single Open+ReadDir, no separate Stat or Readdirnames pass); the
+// directory is only removed, in remove-parent-after-children order, once
+// every one of those entries has itself been removed.
+func (r *Remover) walk(path string, info os.FileInfo) error {
+	if !r.prompt(path, Descend) {
+		return nil
+	}
+	entries, err := r.readDir(path)
+	if err != nil {
+		return err
+	}
+	r.stack = append(r.stack, node{path: path, info: info, entries: entries})
+
+	for len(r.stack) > 0 {
+		top := &r.stack[len(r.stack)-1]
+
+		if top.idx >= len(top.entries) {
+			if err := r.remove(top.path, top.info); err != nil && err != errRefused {
 				return err
 			}
-			r.stack = append(r.stack, node{path: path, info: info, kids: len(files)})
-			return dir.Close()
+			r.stack = r.stack[:len(r.stack)-1]
+			continue
 		}
 
-		err = r.remove(path, info)
+		ent := top.entries[top.idx]
+		top.idx++
 
-		// Work our way down the r.stack.
-		for i := len(r.stack) - 1; i >= 0; i-- {
-			s := &r.stack[i]
-			s.kids--
-			if s.kids != 0 {
-				r.stack = r.stack[:i+1]
-				break
-			}
-			if err := r.remove(s.path, s.info); err != nil && err != errRefused {
+		child := filepath.Join(top.path, ent.Name())
+		childInfo, err := ent.Info()
+		if err != nil {
+			return err
+		}
+
+		if !childInfo.IsDir() {
+			if err := r.remove(child, childInfo); err != nil && err != errRefused {
 				return err
 			}
+			continue
 		}
 
-		if err != nil && err != errRefused {
+		if !r.prompt(child, Descend) {
+			continue
+		}
+		childEntries, err := r.readDir(child)
+		if err != nil {
 			return err
 		}
-		return nil
-	})
+		r.stack = append(r.stack, node{path: child, info: childInfo, entries: childEntries})
+	}
+	return nil
+}
+
+// readDir lists path's entries through r.fsys(); each entry's FileInfo,
+// when needed, comes from the os.DirEntry itself rather than a second Stat
+// pass over the directory.
+func (r *Remover) readDir(path string) ([]os.DirEntry, error) {
+	return r.fsys().ReadDir(path)
 }
 
 var errRefused = errors.New("user refused prompt")
@@ -160,7 +204,22 @@ func (r *Remover) prompt(name string, opts PromptOption) bool {
 	return true
 }
 
-func (r *Remover) rm(name string, dir bool) (err error) {
+// backend returns r.Backend, defaulting to one that removes through
+// r.fsys() (OSFS unless FS is set) when unset.
+func (r *Remover) backend() Backend {
+	if r.Backend == nil {
+		return fsBackend{r.fsys()}
+	}
+	return r.Backend
+}
+
+// fsBackend adapts a RemoveFS's Remove/RemoveDir into a Backend.
+type fsBackend struct{ fsys RemoveFS }
+
+func (b fsBackend) RemoveFile(path string, _ os.FileInfo) error { return b.fsys.Remove(path) }
+func (b fsBackend) RemoveDir(path string, _ os.FileInfo) error  { return b.fsys.RemoveDir(path) }
+
+func (r *Remover) rm(name string, info os.FileInfo, dir bool) (err error) {
 	opts := Remove
 	if dir {
 		opts |= Directory
@@ -169,32 +228,19 @@ func (r *Remover) rm(name string, dir bool) (err error) {
 		return errRefused
 	}
 
-	switch runtime.GOOS {
-	case "windows", "plan9":
-		err = os.Remove(name)
-	default:
-		// For unix systems, os.Remove is a call to Unlink followed by a call to
-		// Rmdir. Since os.Remove doesn't know whether the object is a file or
-		// directory, this provides better performance in the common case. But,
-		// since we know the type of the object ahead of time, we can simply call
-		// the proper syscall.
-		if !dir {
-			err = unix.Unlink(name)
-		} else {
-			err = unix.Rmdir(name)
-		}
-		if err != nil {
-			err = &os.PathError{Op: "remove", Path: name, Err: err}
-		}
+	if dir {
+		err = r.backend().RemoveDir(name, info)
+	} else {
+		err = r.backend().RemoveFile(name, info)
 	}
 	if err != nil && (r.opts&IgnoreMissing == 0 || !os.IsNotExist(err)) {
 		return err
 	}
 	if r.opts&Verbose != 0 {
 		if dir {
-			r.Log <- fmt.Sprintf("removed directory %s")
+			r.Log <- fmt.Sprintf("removed directory %s", name)
 		} else {
-			r.Log <- fmt.Sprintf("removed %s")
+			r.Log <- fmt.Sprintf("removed %s", name)
 		}
 	}
 	return nil
@@ -209,27 +255,55 @@ func (r *Remover) remove(path string, info os.FileInfo) error {
 		case "/":
 			return rmError{msg: "cannot remove root directory"}
 		default:
-			if r.opts&NoPreserveRoot == 0 && sys.IsRoot(info) {
+			if r.opts&NoPreserveRoot == 0 && r.isRoot(info) {
 				return rmError{msg: "cannot remove root directory"}
 			}
 		}
-		if r.opts&Recursive == 0 && (r.opts&RemoveEmpty == 0 || isEmpty(path)) {
+		// TrashBackend moves a directory into the trash as a whole, so it
+		// doesn't need Recursive to have been requested the way unlinking
+		// does.
+		_, trashing := r.backend().(TrashBackend)
+		if !trashing && r.opts&Recursive == 0 && (r.opts&RemoveEmpty == 0 || r.isEmpty(path)) {
 			return rmError{msg: fmt.Sprintf("cannot remove directory: %q", path)}
 		}
-		return r.rm(path, true)
+		return r.rm(path, info, true)
 	}
-	if r.opts&OneFileSystem != 0 && sys.DiffFS(r.root, info) {
+	if r.opts&OneFileSystem != 0 && !r.fsys().SameDevice(r.root, info) {
 		return rmError{msg: "cannot recurse into a different filesystem"}
 	}
-	return r.rm(path, false)
+	if r.opts&Shred != 0 {
+		if err := r.shred(path, info); err != nil && !r.handleError(err) {
+			return err
+		}
+	}
+	return r.rm(path, info, false)
+}
+
+// handleError reports err to r.OnError, if set, and returns whether removal
+// should proceed despite it. With no OnError hook, non-fatal errors like
+// ErrShredUnsupported are treated as fatal.
+func (r *Remover) handleError(err error) bool {
+	if r.OnError == nil {
+		return false
+	}
+	return r.OnError(err)
+}
+
+// isEmpty reports whether path has at least one entry -- named for the
+// RemoveEmpty check it feeds, which only refuses non-recursive removal of
+// directories RemoveEmpty wasn't asked to allow, not truly-empty ones.
+func (r *Remover) isEmpty(path string) bool {
+	entries, err := r.fsys().ReadDir(path)
+	return err == nil && len(entries) != 0
 }
 
-func isEmpty(path string) bool {
-	file, err := os.Open(path)
+// isRoot reports whether info is the filesystem root ("/"), using the
+// injected RemoveFS's SameFile rather than a platform-specific stat
+// comparison.
+func (r *Remover) isRoot(info os.FileInfo) bool {
+	root, err := r.fsys().Stat("/")
 	if err != nil {
 		return false
 	}
-	defer file.Close()
-	names, err := file.Readdirnames(1)
-	return len(names) != 0 && err == nil
+	return r.fsys().SameFile(root, info)
 }
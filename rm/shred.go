@@ -0,0 +1,110 @@
+package rm
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/ericlagergren/go-coreutils/rm/internal/sys"
+	"golang.org/x/sys/unix"
+)
+
+// ErrShredUnsupported is surfaced through Remover.OnError when a file lives
+// on a copy-on-write or log-structured filesystem (btrfs, zfs, f2fs,
+// tmpfs), where overwriting a file's blocks in place isn't guaranteed to
+// touch the blocks its old data actually occupied.
+var ErrShredUnsupported = errors.New("rm: shred unsupported on this filesystem")
+
+// ShredConfig controls the overwrite passes performed by the Shred
+// RemoveOption.
+type ShredConfig struct {
+	// Passes is the number of times a file's contents are overwritten with
+	// data drawn from RandSource before it is unlinked. Less than 1 behaves
+	// like 1.
+	Passes int
+
+	// Zero, if set, does one final all-nul pass after the random passes, so
+	// the file appears empty to a casual observer.
+	Zero bool
+
+	// RandSource supplies the bytes written during each random pass.
+	// Defaults to crypto/rand.Reader.
+	RandSource io.Reader
+}
+
+// shred overwrites path's contents in place per r.ShredCfg. It is a no-op
+// for anything other than a regular file (symlinks, sockets, FIFOs, and
+// device nodes have no file-backed data to overwrite), and returns
+// ErrShredUnsupported, leaving the file untouched, when path lives on a
+// copy-on-write or log-structured filesystem.
+func (r *Remover) shred(path string, info os.FileInfo) error {
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	cow, err := sys.IsCOWFS(path)
+	if err != nil {
+		return err
+	}
+	if cow {
+		return ErrShredUnsupported
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	size := info.Size()
+	rnd := r.ShredCfg.RandSource
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	passes := r.ShredCfg.Passes
+	if passes < 1 {
+		passes = 1
+	}
+
+	for i := 0; i < passes; i++ {
+		if err := overwrite(f, size, rnd); err != nil {
+			return err
+		}
+		if err := unix.Fdatasync(int(f.Fd())); err != nil {
+			return err
+		}
+	}
+
+	if r.ShredCfg.Zero {
+		if err := overwrite(f, size, zeroReader{}); err != nil {
+			return err
+		}
+		if err := unix.Fdatasync(int(f.Fd())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// overwrite rewinds f to the start and copies exactly n bytes read from src
+// into it.
+func overwrite(f *os.File, n int64, src io.Reader) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.CopyN(f, src, n)
+	return err
+}
+
+// zeroReader is an io.Reader of infinite nul bytes, used for ShredConfig's
+// final Zero pass.
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
@@ -0,0 +1,75 @@
+package rm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverwritePasses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	const size = 1024
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	const passes = 3
+	data := make([]byte, passes*size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	src := bytes.NewReader(data)
+
+	for i := 0; i < passes; i++ {
+		if err := overwrite(f, size, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.Len() != 0 {
+		t.Errorf("RandSource not fully consumed: %d bytes left, want every byte written %d times", src.Len(), passes)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := data[(passes-1)*size:]
+	if !bytes.Equal(got, want) {
+		t.Errorf("file contents after final pass don't match the data written in it")
+	}
+}
+
+func TestOverwriteZeroPass(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	const size = 512
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xff}, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := overwrite(f, size, zeroReader{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range got {
+		if b != 0 {
+			t.Fatalf("byte %d: got %#x, want 0x00", i, b)
+		}
+	}
+}
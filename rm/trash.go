@@ -0,0 +1,171 @@
+package rm
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TrashBackend implements the FreeDesktop.org trash specification
+// (https://specifications.freedesktop.org/trash-spec/trashspec-latest.html):
+// instead of unlinking, it moves the target into a trash directory's
+// files/ subdirectory and records its original location and deletion time
+// in a matching info/NAME.trashinfo file. Directories are moved as a
+// whole; see the Recursive note on Remover.remove.
+type TrashBackend struct{}
+
+func (TrashBackend) RemoveFile(path string, info os.FileInfo) error { return trash(path) }
+func (TrashBackend) RemoveDir(path string, info os.FileInfo) error  { return trash(path) }
+
+// trash moves path into the appropriate trash directory for the volume it
+// lives on, and writes its .trashinfo sidecar.
+func trash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	dir, err := trashDirFor(abs)
+	if err != nil {
+		return err
+	}
+	filesDir := filepath.Join(dir, "files")
+	infoDir := filepath.Join(dir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return err
+	}
+
+	dest, infoPath, err := uniqueTrashName(filesDir, infoDir, filepath.Base(abs))
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(abs, dest); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		encodeTrashPath(abs), time.Now().Format("2006-01-02T15:04:05"))
+	return os.WriteFile(infoPath, []byte(content), 0600)
+}
+
+// uniqueTrashName picks a destination under filesDir, and its matching
+// info/NAME.trashinfo under infoDir, that collide with neither an existing
+// files/NAME nor info/NAME.trashinfo, appending "_2", "_3", etc. to base
+// when necessary.
+func uniqueTrashName(filesDir, infoDir, base string) (dest, infoPath string, err error) {
+	name := base
+	for i := 2; ; i++ {
+		dest = filepath.Join(filesDir, name)
+		infoPath = filepath.Join(infoDir, name+".trashinfo")
+		_, destErr := os.Lstat(dest)
+		_, infoErr := os.Lstat(infoPath)
+		if os.IsNotExist(destErr) && os.IsNotExist(infoErr) {
+			return dest, infoPath, nil
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+}
+
+// encodeTrashPath percent-encodes abs the way the trash spec's Path= key
+// requires: every path segment URL-encoded, joined back with "/".
+func encodeTrashPath(abs string) string {
+	segs := strings.Split(filepath.ToSlash(abs), "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// trashDirFor returns the trash directory that should hold abs: the home
+// trash ($XDG_DATA_HOME/Trash, falling back to ~/.local/share/Trash) if abs
+// is on the same device as the home directory, otherwise .Trash-$UID at
+// the root of abs's own mount point, per the trash spec's "top directory
+// trash" fallback.
+func trashDirFor(abs string) (string, error) {
+	home, err := homeTrashDir()
+	if err != nil {
+		return "", err
+	}
+
+	fileDev, err := devOf(filepath.Dir(abs))
+	if err != nil {
+		return "", err
+	}
+	homeDev, err := devOf(filepath.Dir(home))
+	if err != nil {
+		return "", err
+	}
+	if fileDev == homeDev {
+		return home, nil
+	}
+
+	mount, err := mountPointOf(abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mount, fmt.Sprintf(".Trash-%d", os.Getuid())), nil
+}
+
+func homeTrashDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// devOf returns the device number (the same field Remover's OneFileSystem
+// check, sys.DiffFS, compares) of the filesystem path would live on, even
+// if path itself -- e.g. a trash directory not yet created -- doesn't
+// exist, by walking up to the nearest existing ancestor.
+func devOf(path string) (uint64, error) {
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			return info.Sys().(*syscall.Stat_t).Dev, nil
+		}
+		if !os.IsNotExist(err) {
+			return 0, err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0, err
+		}
+		path = parent
+	}
+}
+
+// mountPointOf walks up from path's directory until crossing onto a
+// different device, returning the deepest directory still on path's
+// filesystem.
+func mountPointOf(path string) (string, error) {
+	dir := filepath.Dir(path)
+	dev, err := devOf(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+		pdev, err := devOf(parent)
+		if err != nil {
+			return "", err
+		}
+		if pdev != dev {
+			return dir, nil
+		}
+		dir = parent
+	}
+}
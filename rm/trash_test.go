@@ -0,0 +1,94 @@
+package rm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrashBackendFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	work := t.TempDir()
+	f := filepath.Join(work, "doc.txt")
+	if err := os.WriteFile(f, []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRemover(0)
+	r.Backend = TrashBackend{}
+	if err := r.Remove(f); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f); !os.IsNotExist(err) {
+		t.Fatalf("original file still exists")
+	}
+
+	trashDir := filepath.Join(home, "data", "Trash")
+	entries, err := os.ReadDir(filepath.Join(trashDir, "files"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "doc.txt" {
+		t.Fatalf("unexpected files/ entries: %v", entries)
+	}
+	info, err := os.ReadDir(filepath.Join(trashDir, "info"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info) != 1 || info[0].Name() != "doc.txt.trashinfo" {
+		t.Fatalf("unexpected info/ entries: %v", info)
+	}
+}
+
+func TestTrashBackendDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	work := t.TempDir()
+	d := filepath.Join(work, "proj")
+	if err := os.MkdirAll(filepath.Join(d, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d, "sub", "f.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRemover(0)
+	r.Backend = TrashBackend{}
+	if err := r.Remove(d); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(d); !os.IsNotExist(err) {
+		t.Fatalf("original directory still exists")
+	}
+
+	trashed := filepath.Join(home, "data", "Trash", "files", "proj", "sub", "f.txt")
+	if _, err := os.Stat(trashed); err != nil {
+		t.Fatalf("expected %s to exist: %v", trashed, err)
+	}
+}
+
+func TestUniqueTrashName(t *testing.T) {
+	dir := t.TempDir()
+	filesDir := filepath.Join(dir, "files")
+	infoDir := filepath.Join(dir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filesDir, "a.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest, _, err := uniqueTrashName(filesDir, infoDir, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(filesDir, "a.txt_2"); dest != want {
+		t.Errorf("got %q, want %q", dest, want)
+	}
+}
@@ -29,11 +29,16 @@ With no FILE, or when FILE is -, read standard input.
   -t, --text    read in text mode
   Note: there is no difference between text and binary mode option.
 
-The following three options are useful only when verifying checksums:
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
       --quiet    don't print OK for each successfully verified file
       --status   don't output anything, status code shows success
+      --strict   exit non-zero for improperly formatted lines
   -w, --warn     warn about improperly formated checksum lines
 
+      --tag      create a BSD-style checksum
+  -z, --zero     end each output line with NUL, not newline
+  -j, --jobs=N   hash N files concurrently (default: run serially)
       --help     show help and exit
       --version  show version and exit
 
@@ -46,9 +51,10 @@ package main
 
 import (
 	"fmt"
-	cc "github.com/fangdingjun/go-coreutils/md5sum/checksum_common"
-	flag "github.com/ogier/pflag"
 	"os"
+
+	cc "github.com/ericlagergren/go-coreutils/md5sum/checksum_common"
+	flag "github.com/ogier/pflag"
 )
 
 const (
@@ -61,18 +67,23 @@ With no FILE, or when FILE is -, read standard input.
   -t, --text    read in text mode
   Note: there is no difference between text and binary mode option.
 
-The following three options are useful only when verifying checksums:
+The following options are useful only when verifying checksums:
+      --ignore-missing  don't fail or report status for missing files
       --quiet    don't print OK for each successfully verified file
       --status   don't output anything, status code shows success
+      --strict   exit non-zero for improperly formatted lines
   -w, --warn     warn about improperly formated checksum lines
-  
+
+      --tag      create a BSD-style checksum
+  -z, --zero     end each output line with NUL, not newline
+  -j, --jobs=N   hash N files concurrently (default: run serially)
       --help     show help and exit
       --version  show version and exit
 
 The sums are computed as described in FIPS-180-2.  When checking, the input
 should be a former output of this program.  The default mode is to print
 a line with checksum, a character indicating type ('*' for binary, ' ' for
-text), and name for each FILE.      
+text), and name for each FILE.
 `
 	Version = `sha512sum (Go coreutils) 0.1
 Copyright (C) 2015 Dingjun Fang
@@ -83,13 +94,18 @@ There is NO WARRANTY, to the extent permitted by law.
 )
 
 var (
-	check_sum    = flag.BoolP("check", "c", false, "")
-	no_output    = flag.BoolP("quiet", "q", false, "")
-	no_output_s  = flag.BoolP("status", "", false, "")
-	show_warn    = flag.BoolP("warn", "w", true, "")
-	show_version = flag.BoolP("version", "v", false, "")
-	text_mode    = flag.BoolP("text", "t", false, "")
-	binary_mode  = flag.BoolP("binary", "b", false, "")
+	check_sum      = flag.BoolP("check", "c", false, "")
+	no_output      = flag.BoolP("quiet", "q", false, "")
+	no_output_s    = flag.BoolP("status", "", false, "")
+	show_warn      = flag.BoolP("warn", "w", true, "")
+	show_version   = flag.BoolP("version", "v", false, "")
+	text_mode      = flag.BoolP("text", "t", false, "")
+	binary_mode    = flag.BoolP("binary", "b", false, "")
+	tag            = flag.BoolP("tag", "", false, "")
+	zero           = flag.BoolP("zero", "z", false, "")
+	ignore_missing = flag.BoolP("ignore-missing", "", false, "")
+	strict         = flag.BoolP("strict", "", false, "")
+	jobs           = flag.IntP("jobs", "j", 0, "")
 )
 
 func main() {
@@ -116,13 +132,36 @@ func main() {
 	case *show_version:
 		fmt.Fprintf(os.Stdout, "%s", Version)
 		os.Exit(0)
+	case *check_sum && *jobs > 0:
+		cfg := cc.Config{
+			Status:        *no_output,
+			Warn:          *show_warn,
+			Zero:          *zero,
+			IgnoreMissing: *ignore_missing,
+			Strict:        *strict,
+		}
+		if r := cc.CompareChecksumParallel(file_lists, "sha512", cfg, *jobs); !r {
+			has_error = true
+		}
 	case *check_sum:
-		if r := cc.CompareChecksum(file_lists, "sha512",
-			!(*no_output), *show_warn); !r {
+		cfg := cc.Config{
+			Status:        *no_output,
+			Warn:          *show_warn,
+			Zero:          *zero,
+			IgnoreMissing: *ignore_missing,
+			Strict:        *strict,
+		}
+		if r := cc.CompareChecksum(file_lists, "sha512", cfg); !r {
+			has_error = true
+		}
+	case *jobs > 0:
+		cfg := cc.Config{Tag: *tag, Zero: *zero}
+		if r := cc.GenerateChecksumParallel(file_lists, "sha512", cfg, *jobs); !r {
 			has_error = true
 		}
 	default:
-		if r := cc.GenerateChecksum(file_lists, "sha512"); !r {
+		cfg := cc.Config{Tag: *tag, Zero: *zero}
+		if r := cc.GenerateChecksum(file_lists, "sha512", cfg); !r {
 			has_error = true
 		}
 	}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+)
+
+// itemHeap is a container/heap priority queue of ready items (count == 0),
+// ordered lexicographically by str so tsortStable always peels the
+// smallest ready item first.
+type itemHeap []*item
+
+func (h itemHeap) Len() int            { return len(h) }
+func (h itemHeap) Less(i, j int) bool  { return h[i].str < h[j].str }
+func (h itemHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *itemHeap) Push(x interface{}) { *h = append(*h, x.(*item)) }
+
+func (h *itemHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	*h = old[:n-1]
+	return it
+}
+
+// tsortStable is the -s/--stable counterpart to the main peel loop in
+// tsort: instead of a FIFO qlink chain, ready items wait in a min-heap
+// keyed on item.str, so ties are always broken in lexicographic order and
+// the result is reproducible regardless of input ordering.
+func tsortStable(rw io.ReadWriter, root *item) int {
+	var ok int
+
+	h := &itemHeap{}
+	heap.Init(h)
+
+	enqueue := func(it *item) {
+		if it.count == 0 && it.str != "" && !it.queued {
+			it.queued = true
+			heap.Push(h, it)
+		}
+	}
+
+	root.walkTree(func(it *item) bool {
+		enqueue(it)
+		return false
+	})
+
+	remaining := numStrings
+	for remaining > 0 {
+		if h.Len() == 0 {
+			ok = 1
+
+			for {
+				root.walkTree(detectLoop)
+				if loop == nil {
+					break
+				}
+			}
+
+			root.walkTree(func(it *item) bool {
+				enqueue(it)
+				return false
+			})
+
+			if h.Len() == 0 {
+				break
+			}
+			continue
+		}
+
+		it := heap.Pop(h).(*item)
+		fmt.Fprintln(rw, it.str)
+		it.str = ""
+		remaining--
+
+		for p := it.top; p != nil; p = p.next {
+			p.suc.count--
+			enqueue(p.suc)
+		}
+	}
+
+	return ok
+}
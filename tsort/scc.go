@@ -0,0 +1,82 @@
+package main
+
+// tarjanSCC computes the strongly connected components of the successor
+// graph built by recordRelation (an edge j->k exists for every "j k" pair
+// in the input), using Tarjan's algorithm. It returns every component that
+// represents a cycle -- two or more members, or a single member with a
+// direct self-successor -- in the order discovered, so all cycles can be
+// reported at once instead of one edge at a time.
+func tarjanSCC(root *item) [][]string {
+	var (
+		index   int
+		stack   []*item
+		onStack = make(map[*item]bool)
+		indices = make(map[*item]int)
+		lowlink = make(map[*item]int)
+		sccs    [][]string
+	)
+
+	var strongconnect func(v *item)
+	strongconnect = func(v *item) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for p := v.top; p != nil; p = p.next {
+			w := p.suc
+			if _, seen := indices[w]; !seen {
+				strongconnect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] {
+				if indices[w] < lowlink[v] {
+					lowlink[v] = indices[w]
+				}
+			}
+		}
+
+		if lowlink[v] == indices[v] {
+			var comp []*item
+			for {
+				n := len(stack) - 1
+				w := stack[n]
+				stack = stack[:n]
+				onStack[w] = false
+				comp = append(comp, w)
+				if w == v {
+					break
+				}
+			}
+
+			if len(comp) > 1 || selfLoop(v) {
+				names := make([]string, len(comp))
+				for i, it := range comp {
+					names[i] = it.str
+				}
+				sccs = append(sccs, names)
+			}
+		}
+	}
+
+	root.walkTree(func(it *item) bool {
+		if _, seen := indices[it]; !seen && it.str != "" {
+			strongconnect(it)
+		}
+		return false
+	})
+
+	return sccs
+}
+
+// selfLoop reports whether v is its own successor.
+func selfLoop(v *item) bool {
+	for p := v.top; p != nil; p = p.next {
+		if p.suc == v {
+			return true
+		}
+	}
+	return false
+}
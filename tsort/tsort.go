@@ -37,6 +37,7 @@ const (
 Write totally ordered list consistent with the partial ordering in FILE.
 With no FILE, or when FILE is -, read standard input.
 
+  -s, --stable   lexicographically smallest topological order
       --help     display this help and exit
       --version  output version information and exit
 Report wc bugs to ericscottlagergren@gmail.com
@@ -52,7 +53,8 @@ There is NO WARRANTY, to the extent permitted by law.
 )
 
 var (
-	version = flag.BoolP("version", "v", false, "")
+	version    = flag.BoolP("version", "v", false, "")
+	stableFlag = flag.BoolP("stable", "s", false, "lexicographically smallest topological order")
 
 	fatal = log.New(os.Stderr, "", 0)
 )
@@ -70,6 +72,7 @@ type item struct {
 	count   int64
 	qlink   *item
 	top     *successor
+	queued  bool // used by tsortStable to avoid double-queueing
 }
 
 type action func(*item) bool
@@ -237,10 +240,9 @@ func scanZeros(k *item) bool {
 	return false
 }
 
-// Try and detect loops. e.g.,
-// 1 2
-// 2 1
-// If any are found, print to stderr.
+// detectLoop breaks one edge of a cycle so the sorting pass can make
+// progress. Full cycle reporting happens up front via tarjanSCC; this only
+// needs to clear the way.
 func detectLoop(k *item) bool {
 	if k.count > 0 {
 		if loop == nil {
@@ -255,8 +257,6 @@ func detectLoop(k *item) bool {
 						for loop != nil {
 							tmp := loop.qlink
 
-							fatal.Printf("tsort: %s", loop.str)
-
 							if loop == k {
 								(*p).suc.count--
 								*p = (*p).next
@@ -321,7 +321,7 @@ func (root *item) walkTree(fn action) {
 	}
 }
 
-func tsort(rw io.ReadWriter) int {
+func tsort(rw io.ReadWriter, stable bool) int {
 
 	var (
 		root = newItem("")
@@ -359,6 +359,23 @@ func tsort(rw io.ReadWriter) int {
 
 	root.walkTree(countItems)
 
+	if sccs := tarjanSCC(root); len(sccs) > 0 {
+		ok = 1
+		for _, comp := range sccs {
+			fatal.Print("tsort: cycle in data")
+			for _, name := range comp {
+				fatal.Print(name)
+			}
+		}
+	}
+
+	if stable {
+		if r := tsortStable(rw, root); r > ok {
+			ok = r
+		}
+		return ok
+	}
+
 	for numStrings > 0 {
 
 		root.walkTree(scanZeros)
@@ -385,7 +402,6 @@ func tsort(rw io.ReadWriter) int {
 		}
 
 		if numStrings > 0 {
-			fatal.Print("tsort: input contains a loop:")
 			ok = 1
 
 			for {
@@ -436,5 +452,5 @@ func main() {
 	}{
 		file,
 		os.Stdout,
-	}))
+	}, *stableFlag))
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"log"
+	"strings"
 	"testing"
 )
 
@@ -58,10 +60,87 @@ func TestTsort(t *testing.T) {
 
 		buf.WriteString(unsorted)
 
-		tsort(&buf)
+		tsort(&buf, false)
 
 		if buf.String() != sorted {
 			t.Errorf("Got: %q\n\nWanted: %q", buf.String(), sorted)
 		}
 	}
 }
+
+// TestTsortCycles checks that a cyclic input still produces a valid
+// topological order over whatever's left once the cycle's edges are
+// broken, and that each cycle found is reported to stderr as
+// "tsort: cycle in data" followed by its member names, one per line,
+// in the order tarjanSCC discovered them.
+func TestTsortCycles(t *testing.T) {
+	cases := []struct {
+		in         string
+		wantOut    string
+		wantCycles [][]string
+	}{
+		{
+			in:         "a b\nb a",
+			wantOut:    "a\nb\n",
+			wantCycles: [][]string{{"b", "a"}},
+		},
+		{
+			in:         "1 2\n2 3\n3 1",
+			wantOut:    "1\n2\n3\n",
+			wantCycles: [][]string{{"3", "2", "1"}},
+		},
+		{
+			in:         "x y\ny z\nz x\nz w",
+			wantOut:    "x\ny\nz\nw\n",
+			wantCycles: [][]string{{"z", "y", "x"}},
+		},
+	}
+
+	for _, c := range cases {
+		head, zeros, loop, numStrings = nil, nil, nil, 0
+
+		var errBuf bytes.Buffer
+		realFatal := fatal
+		fatal = log.New(&errBuf, "", 0)
+
+		var buf bytes.Buffer
+		buf.WriteString(c.in)
+
+		rc := tsort(&buf, false)
+		fatal = realFatal
+
+		if rc == 0 {
+			t.Errorf("%q: got exit code 0, want nonzero for a cyclic input", c.in)
+		}
+		if buf.String() != c.wantOut {
+			t.Errorf("%q: got stdout %q, want %q", c.in, buf.String(), c.wantOut)
+		}
+
+		var wantErr strings.Builder
+		for _, comp := range c.wantCycles {
+			wantErr.WriteString("tsort: cycle in data\n")
+			for _, name := range comp {
+				wantErr.WriteString(name)
+				wantErr.WriteByte('\n')
+			}
+		}
+		if errBuf.String() != wantErr.String() {
+			t.Errorf("%q: got stderr %q, want %q", c.in, errBuf.String(), wantErr.String())
+		}
+	}
+}
+
+func TestTsortStable(t *testing.T) {
+	head, zeros, loop, numStrings = nil, nil, nil, 0
+
+	var buf bytes.Buffer
+	buf.WriteString("b a\na c\nb d")
+
+	if tsort(&buf, true) != 0 {
+		t.Fatalf("unexpected cycle")
+	}
+
+	if got, want := buf.String(), "b\na\nc\nd\n"; got != want {
+		t.Errorf("Got: %q\n\nWanted: %q", got, want)
+	}
+}
@@ -2,13 +2,52 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"os"
+	"time"
 
-	"github.com/EricLagerg/go-gnulib/utmp"
+	"github.com/EricLagergren/go-gnulib/utmp"
+
+	iuptime "github.com/ericlagergren/go-coreutils/internal/uptime"
 
 	flag "github.com/ogier/pflag"
 )
 
+const (
+	Help1 = `Usage: uptime [OPTION]... [FILE]
+Print the current time, the length of time the system has been up,
+the number of users on the system, and the average number of jobs
+in the run queue over the last 1, 5 and 15 minutes.  Processes in
+an uninterruptible sleep state also contribute to the load average.
+If FILE is not specified, use`
+	Help2 = `as FILE is common.
+
+      --help     display this help and exit
+      --version  output version information and exit
+
+Report wc bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagergren/go-coreutils/>
+`
+
+	Version = `
+	uptime (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+`
+
+	delim = " "
+)
+
+var (
+	version = flag.BoolP("version", "v", false, "")
+
+	// fatal = log.New(os.Stderr, "", log.Lshortfile)
+	fatal = log.New(os.Stderr, "", 0)
+)
+
 func main() {
 	flag.Usage = func() {
 		// This is a little weird because I want to insert the correct
@@ -35,3 +74,55 @@ func main() {
 		fatal.Fatalf("extra operand %s\n", flag.Arg(1))
 	}
 }
+
+// printUptime writes the same line the real uptime(1) does -- the
+// current time, how long the system has been up, the user count, and
+// the load averages -- reading boot time/uptime/load from src rather
+// than poking at OS-specific syscalls directly, so this function itself
+// has nothing left that's platform-specific. entries is the number of
+// logged-in user processes, counted by the caller since utmp's entry
+// type (and how to query it) differs by OS.
+func printUptime(src iuptime.Source, entries int64) {
+	up, err := src.Uptime()
+	if err != nil {
+		fatal.Fatalln(err)
+	}
+
+	secs := up.Seconds()
+	days := int(secs) / 86400
+	hours := (int(secs) - (days * 86400)) / 3600
+	mins := (int(secs) - (days * 86400) - (hours * 3600)) / 60
+
+	// "15" is already the zero-padded 24-hour hour; pairing it with "pm"
+	// pasted on a 12-hour suffix that Go's reference-time formatter
+	// never actually substitutes, so every line printed "pm" regardless
+	// of the real time of day. Drop the bogus suffix and print a plain
+	// 24-hour clock, as uptime(1) does.
+	os.Stdout.WriteString(time.Now().Local().Format(" 15:04  "))
+
+	if secs < 0 || secs >= math.MaxFloat64 {
+		os.Stdout.WriteString("up ???? days ??:??,  ")
+	} else if days > 0 {
+		fmt.Printf(GetPlural("up %d day %2d:%02d,  ",
+			"up %d days %2d:%02d,  ", uint64(days)), days, hours, mins)
+	} else {
+		fmt.Printf("up  %2d:%02d,  ", hours, mins)
+	}
+
+	fmt.Printf(GetPlural("%d user", "%d users", uint64(entries)), entries)
+
+	avg, err := src.LoadAvg()
+	if err != nil {
+		fmt.Println()
+		return
+	}
+	fmt.Printf(",  load average: %.2f, %.2f, %.2f\n", avg[0], avg[1], avg[2])
+}
+
+// GetPlural picks msg1 for a count of exactly one, msg2 otherwise.
+func GetPlural(msg1, msg2 string, n uint64) string {
+	if n == 1 {
+		return msg1
+	}
+	return msg2
+}
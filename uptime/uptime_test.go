@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	iuptime "github.com/ericlagergren/go-coreutils/internal/uptime"
+)
+
+// capturePrintUptime runs printUptime with stdout redirected to a pipe
+// and returns everything it wrote.
+func capturePrintUptime(t *testing.T, src iuptime.Source, entries int64) string {
+	t.Helper()
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	outC := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		outC <- b.String()
+	}()
+
+	printUptime(src, entries)
+
+	w.Close()
+	os.Stdout = stdout
+	return <-outC
+}
+
+func TestPrintUptime(t *testing.T) {
+	cases := []struct {
+		name    string
+		src     iuptime.FakeSource
+		entries int64
+		want    string
+	}{
+		{
+			name:    "under a day, one user",
+			src:     iuptime.FakeSource{Up: 90*time.Minute + 12*time.Second, Avg: [3]float64{0.5, 0.25, 0.1}},
+			entries: 1,
+			want:    "up   1:30,  1 user,  load average: 0.50, 0.25, 0.10\n",
+		},
+		{
+			name:    "multiple days, multiple users",
+			src:     iuptime.FakeSource{Up: 2*24*time.Hour + 3*time.Hour + 4*time.Minute, Avg: [3]float64{1.0, 2.0, 3.0}},
+			entries: 3,
+			want:    "up 2 days  3:04,  3 users,  load average: 1.00, 2.00, 3.00\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := capturePrintUptime(t, c.src, c.entries)
+
+			// The leading " HH:MM  " clock is nondeterministic;
+			// everything from "up" on is not.
+			i := strings.Index(out, "up")
+			if i < 0 {
+				t.Fatalf("output has no \"up\" field: %q", out)
+			}
+			if got := out[i:]; got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
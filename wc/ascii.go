@@ -0,0 +1,57 @@
+package wc
+
+import "encoding/binary"
+
+// asciiClass categorizes a single ASCII byte the same way
+// processChunk's switch over a decoded rune does, so the ASCII
+// fast path in processChunk can use a table lookup instead of
+// paying for utf8.DecodeRune and unicode.IsPrint/IsSpace on input it
+// already knows is plain ASCII.
+type asciiClass uint8
+
+const (
+	asciiOther asciiClass = iota // control chars and DEL: counted as a char, nothing else
+	asciiPrint                   // visible, non-whitespace
+	asciiSpace                   // ' '
+	asciiVTab                    // '\v'
+	asciiTab                     // '\t'
+	asciiCRFF                    // '\r', '\f'
+	asciiLF                      // '\n'
+)
+
+var asciiClassTable = func() (t [128]asciiClass) {
+	for i := 0x21; i < 0x7f; i++ {
+		t[i] = asciiPrint
+	}
+	t[' '] = asciiSpace
+	t['\v'] = asciiVTab
+	t['\t'] = asciiTab
+	t['\r'] = asciiCRFF
+	t['\f'] = asciiCRFF
+	t['\n'] = asciiLF
+	return t
+}()
+
+// isASCII reports whether every byte in b is < 0x80. It checks eight
+// bytes at a time (the high bit of every byte in a word is non-zero
+// iff the word ANDed with 0x80...80 is non-zero) so the common,
+// pure-ASCII case doesn't pay a per-byte branch just to confirm it.
+//
+// This is plain Go rather than SSE2/AVX2 asm: the rest of this repo
+// has no assembly anywhere, and the word-at-a-time trick already gets
+// most of the win without a new, architecture-specific code path to
+// maintain.
+func isASCII(b []byte) bool {
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		if binary.LittleEndian.Uint64(b[i:i+8])&0x8080808080808080 != 0 {
+			return false
+		}
+	}
+	for ; i < len(b); i++ {
+		if b[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package wc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsASCII(t *testing.T) {
+	cases := []struct {
+		s    string
+		want bool
+	}{
+		{"", true},
+		{"hello world", true},
+		{strings.Repeat("x", 17), true}, // not a multiple of 8, exercises the tail loop
+		{"hello\xffworld", false},
+		{"\x80", false},
+		{strings.Repeat("x", 16) + "\xc3\xa9", false}, // non-ASCII only past the word-sized prefix
+	}
+	for _, c := range cases {
+		if got := isASCII([]byte(c.s)); got != c.want {
+			t.Errorf("isASCII(%q) = %v, want %v", c.s, got, c.want)
+		}
+	}
+}
+
+// TestCounterASCIIFastPathAllClasses feeds every asciiClass byte kind
+// except asciiOther (the input has no control character) through
+// Counter.Count and checks Lines/Words/Chars/
+// MaxLength against values worked out by hand, so a mistake in the
+// lookup table (as opposed to a mistake only the slow utf8 loop would
+// hit) gets caught.
+func TestCounterASCIIFastPathAllClasses(t *testing.T) {
+	// "ab cd\tef\n\v\rgh\f" -- one line "ab cd\tef", a vertical tab and
+	// a carriage return with nothing between them, then "gh" ended by
+	// a form feed.
+	const input = "ab cd\tef\n\v\rgh\f"
+
+	c := NewCounter(Lines | Words | Chars | MaxLength)
+	res, err := c.Count(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := res.Lines, int64(1); got != want {
+		t.Errorf("Lines = %d, want %d", got, want)
+	}
+	if got, want := res.Words, int64(4); got != want { // ab, cd, ef, gh
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+	if got, want := res.Chars, int64(len(input)); got != want {
+		t.Errorf("Chars = %d, want %d", got, want)
+	}
+	// "ab cd" expands the tab from column 5 to column 8, then "ef" for
+	// columns 8-9: longest line is "ab cd\tef" at 10 columns wide.
+	if got, want := res.MaxLength, int64(10); got != want {
+		t.Errorf("MaxLength = %d, want %d", got, want)
+	}
+}
+
+// TestCounterASCIIFastPathAcrossChunkBoundary builds ASCII input that
+// spans more than one Counter.buf-sized read and checks the per-chunk
+// fast path keeps state (pos, inword) correctly across reads, the same
+// way the slow loop always has.
+func TestCounterASCIIFastPathAcrossChunkBoundary(t *testing.T) {
+	const lineLen = 100
+	const numLines = 4000 // well over 1<<17 bytes total
+	line := strings.Repeat("a", lineLen-1) + "\n"
+	input := strings.Repeat(line, numLines)
+
+	c := NewCounter(Lines | Words | MaxLength)
+	res, err := c.Count(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Lines, int64(numLines); got != want {
+		t.Errorf("Lines = %d, want %d", got, want)
+	}
+	if got, want := res.Words, int64(numLines); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+	if got, want := res.MaxLength, int64(lineLen-1); got != want {
+		t.Errorf("MaxLength = %d, want %d", got, want)
+	}
+}
+
+// TestCounterASCIIFastPathWordsUAX29AndGraphemes checks that ASCII
+// input still drives the wordBreaker/graphemeBreaker state machines
+// correctly from inside the fast path, not just the slow one.
+func TestCounterASCIIFastPathWordsUAX29AndGraphemes(t *testing.T) {
+	const input = "hello world\n42 apples\n"
+
+	c := NewCounter(WordsUAX29 | Graphemes)
+	res, err := c.Count(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(4); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+	if got, want := res.Graphemes, int64(len(input)); got != want {
+		t.Errorf("Graphemes = %d, want %d", got, want)
+	}
+}
+
+// TestCounterASCIIFastPathOptionCombinations feeds the same ASCII text
+// through every relevant option combination and checks only that Count
+// succeeds for each; it does not compare the results against the
+// Unicode path.
+func TestCounterASCIIFastPathOptionCombinations(t *testing.T) {
+	const input = "The quick brown\tfox\vjumps\rover   the lazy dog.\n\n"
+	for _, opts := range []uint8{
+		Words,
+		Chars,
+		MaxLength,
+		Words | Chars | MaxLength,
+		WordsUAX29,
+		Graphemes,
+	} {
+		c := NewCounter(opts)
+		if _, err := c.Count(strings.NewReader(input)); err != nil {
+			t.Errorf("opts=%d: %v", opts, err)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"math"
 	"math/bits"
 	"os"
+	"runtime"
+	"sync"
 	"unicode"
 
 	coreutils "github.com/ericlagergren/go-coreutils"
@@ -26,25 +28,45 @@ func newCommand() *cmd {
 	c.f.BoolVarP(&c.chars, "chars", "m", false, "print the character counts")
 	c.f.BoolVarP(&c.bytes, "bytes", "c", false, "print the byte counts")
 	c.f.BoolVarP(&c.maxLength, "max-line-length", "L", false, "print the length of the longest line")
+	c.f.BoolVarP(&c.grapheme, "grapheme", "g", false, "print the grapheme cluster counts (UAX #29)")
+	c.f.BoolVar(&c.wordsUAX29, "words-uax29", false, "count words using UAX #29 word boundaries instead of whitespace splitting")
+	c.f.BoolVar(&c.ambiguousWide, "ambiguous-wide", false, "treat East Asian Ambiguous-width characters as 2 columns wide")
 	c.f.StringVar(&c.filesFrom, "files0-from", "", `read input from the files specified by
                              NUL-terminated names in file F;
                              If F is - then read names from standard input`)
 	c.f.Int64VarP(&c.tabWidth, "tab", "t", 8, "change the tab width")
 	c.f.BoolVarP(&c.unicode, "unicode-version", "u", false, "display unicode version and exit")
+	c.f.IntVarP(&c.parallel, "parallel", "P", runtime.NumCPU(), "count up to N files concurrently")
+	c.f.BoolVarP(&c.decompress, "decompress", "Z", false, "decompress gzip/bzip2/xz/zstd input (detected by magic bytes) before counting")
+	c.f.BoolVar(&c.noDecompress, "no-decompress", false, "never decompress input, even if --decompress is also given")
+	c.f.StringVar(&c.format, "format", formatDefault, `output format: "default", "json", "ndjson", "csv", or "tsv"`)
+	c.f.StringVar(&c.mmap, "mmap", "auto", `map regular files into memory instead of reading them: "auto", "always", or "never"`)
+	c.f.BoolVarP(&c.recursive, "recursive", "r", false, "recurse into directory operands, counting each regular file found")
+	c.f.StringArrayVar(&c.includes, "include", nil, "only count files whose name matches this glob when recursing (repeatable)")
+	c.f.StringArrayVar(&c.excludes, "exclude", nil, "skip files whose name matches this glob when recursing, applied after --include (repeatable)")
+	c.f.BoolVar(&c.hidden, "hidden", false, "include hidden files and directories (dot-prefixed names) when recursing")
+	c.f.BoolVar(&c.followSymlinks, "follow-symlinks", false, "follow symlinks to files and directories when recursing")
 	return &c
 }
 
 type cmd struct {
 	f                                     flag.FlagSet
 	lines, words, chars, bytes, maxLength bool
+	grapheme, wordsUAX29, ambiguousWide   bool
 	filesFrom                             string
 	tabWidth                              int64
 	unicode                               bool
+	parallel                              int
+	decompress, noDecompress              bool
+	format                                string
+	mmap                                  string
+	recursive, hidden, followSymlinks     bool
+	includes, excludes                    []string
 }
 
 var errMixedArgs = errors.New("file operands cannot be combined with --files0-from")
 
-func run(ctx coreutils.Ctx, args ...string) error {
+func run(ctx coreutils.Context, args ...string) error {
 	c := newCommand()
 
 	// TODO(eric): usage
@@ -55,6 +77,7 @@ func run(ctx coreutils.Ctx, args ...string) error {
 
 	if c.unicode {
 		fmt.Fprintf(ctx.Stdout, "Unicode version: %s\n", unicode.Version)
+		fmt.Fprintf(ctx.Stdout, "UAX #29 revision: %s\n", UAX29Revision)
 		return nil
 	}
 
@@ -74,13 +97,29 @@ func run(ctx coreutils.Ctx, args ...string) error {
 	if c.maxLength {
 		opts |= MaxLength
 	}
+	if c.grapheme {
+		opts |= Graphemes
+	}
+	if c.wordsUAX29 {
+		opts |= WordsUAX29
+	}
 
 	if opts == 0 {
 		opts = Lines | Words | Bytes
 	}
 
-	ctr := NewCounter(opts)
-	ctr.TabWidth = c.tabWidth
+	if !validFormat(c.format) {
+		fmt.Fprintln(ctx.Stderr, errInvalidFormat)
+		return errInvalidFormat
+	}
+
+	mmapMode, validMMap := ParseMMapMode(c.mmap)
+	if !validMMap {
+		fmt.Fprintln(ctx.Stderr, errInvalidMMap)
+		return errInvalidMMap
+	}
+
+	doDecompress := c.decompress && !c.noDecompress
 
 	var s interface {
 		Scan() bool
@@ -89,16 +128,58 @@ func run(ctx coreutils.Ctx, args ...string) error {
 	var hint int // To keep from allocating, if possible.
 	if c.filesFrom == "" {
 		if c.f.NArg() == 0 {
-			res, err := ctr.Count(ctx.Stdin)
+			// Stdin can't be split across workers, so it always runs
+			// on the calling goroutine with a Counter of its own.
+			ctr := NewCounter(opts)
+			ctr.TabWidth = c.tabWidth
+			ctr.AmbiguousWide = c.ambiguousWide
+			ctr.MMap = mmapMode
+
+			in := ctx.Stdin
+			if doDecompress {
+				format, peeked, err := sniffCompression(in)
+				if err != nil {
+					fmt.Fprintln(ctx.Stderr, err)
+					return err
+				}
+				in = peeked
+				if format != notCompressed {
+					dr, err := decompressStream(format, peeked)
+					if err != nil {
+						fmt.Fprintln(ctx.Stderr, err)
+						return err
+					}
+					if closer, ok := dr.(io.Closer); ok {
+						defer closer.Close()
+					}
+					in = dr
+				}
+			}
+
+			res, err := ctr.Count(in)
 			if err != nil {
 				fmt.Fprintln(ctx.Stderr, err)
 				return err
 			}
-			width := 7
-			if opts&(opts-1) == 0 { // power of 2, so 1 argument set.
-				width = 1
+
+			switch c.format {
+			case formatJSON:
+				writeJSON(ctx.Stdout, opts, []string{""}, []Results{res}, []bool{true}, res)
+			case formatNDJSON:
+				writeNDJSON(ctx.Stdout, opts, res, "")
+			case formatCSV:
+				writeDelimitedHeader(ctx.Stdout, ',', opts)
+				writeDelimited(ctx.Stdout, ',', opts, res, "")
+			case formatTSV:
+				writeDelimitedHeader(ctx.Stdout, '\t', opts)
+				writeDelimited(ctx.Stdout, '\t', opts, res, "")
+			default:
+				width := 7
+				if opts&(opts-1) == 0 { // power of 2, so 1 argument set.
+					width = 1
+				}
+				writeCounts(ctx.Stdout, width, opts, res, "")
 			}
-			writeCounts(ctx.Stdout, width, opts, res, "")
 			return nil
 		}
 		s = &sliceScanner{s: c.f.Args()}
@@ -118,52 +199,53 @@ func run(ctx coreutils.Ctx, args ...string) error {
 		s.(*bufio.Scanner).Split(filesFromSplit)
 	}
 
+	names := make([]string, 0, hint)
+	for s.Scan() {
+		names = append(names, s.Text())
+	}
+
+	names, err := expandNames(names, c.recursive, c.includes, c.excludes, c.hidden, c.followSymlinks)
+	if err != nil {
+		fmt.Fprintln(ctx.Stderr, err)
+		return err
+	}
+
+	results, regular, compressed, errs := countFiles(names, opts, c.tabWidth, c.ambiguousWide, c.parallel, doDecompress, mmapMode)
+
 	var (
-		results  = make([]Results, 0, hint)
-		names    = make([]string, 0, hint)
 		total    Results
 		maxBytes int64
 		minWidth = 1
+		ok       = 0 // files actually counted, for the "total" line
+		failed   error
 	)
-
-	for s.Scan() {
-		fname := s.Text()
-
-		file, err := os.Open(fname)
-		if err != nil {
-			fmt.Fprintln(ctx.Stderr, err)
-			return err
+	for i, name := range names {
+		if err := errs[i]; err != nil {
+			fmt.Fprintf(ctx.Stderr, "%s: %s\n", name, err)
+			failed = err
+			continue
 		}
+		ok++
 
-		stat, err := file.Stat()
-		if err != nil || (err == nil && !stat.Mode().IsRegular()) {
+		// A compressed file's on-disk size says nothing about its
+		// decompressed byte count, so it gets the same "can't predict
+		// the width" treatment as a non-regular file (a pipe, a
+		// device, etc).
+		if !regular[i] || compressed[i] {
 			minWidth = 7
 		}
 
-		res, err := ctr.Count(file)
-		if err != nil {
-			fmt.Fprintln(ctx.Stderr, err)
-			return err
-		}
-		results = append(results, res)
-		names = append(names, fname)
-
-		total.Lines += res.Lines
-		total.Words += res.Words
-		total.Chars += res.Chars
-		total.Bytes += res.Bytes
+		r := results[i]
+		total.Lines += r.Lines
+		total.Words += r.Words
+		total.Chars += r.Chars
+		total.Bytes += r.Bytes
 
-		if res.Bytes > maxBytes {
-			maxBytes = res.Bytes
+		if r.Bytes > maxBytes {
+			maxBytes = r.Bytes
 		}
-
-		if res.MaxLength > total.MaxLength {
-			total.MaxLength = res.MaxLength
-		}
-
-		if err := file.Close(); err != nil {
-			fmt.Fprintln(ctx.Stderr, err)
-			return err
+		if r.MaxLength > total.MaxLength {
+			total.MaxLength = r.MaxLength
 		}
 	}
 
@@ -177,13 +259,146 @@ func run(ctx coreutils.Ctx, args ...string) error {
 	if width < minWidth {
 		width = minWidth
 	}
-	for i, r := range results {
-		writeCounts(ctx.Stdout, width, opts, r, names[i])
+	switch c.format {
+	case formatJSON:
+		included := make([]bool, len(names))
+		for i := range names {
+			included[i] = errs[i] == nil
+		}
+		writeJSON(ctx.Stdout, opts, names, results, included, total)
+	case formatNDJSON:
+		for i, name := range names {
+			if errs[i] != nil {
+				continue
+			}
+			writeNDJSON(ctx.Stdout, opts, results[i], name)
+		}
+		if ok > 1 {
+			writeNDJSON(ctx.Stdout, opts, total, "total")
+		}
+	case formatCSV, formatTSV:
+		sep := ','
+		if c.format == formatTSV {
+			sep = '\t'
+		}
+		writeDelimitedHeader(ctx.Stdout, sep, opts)
+		for i, name := range names {
+			if errs[i] != nil {
+				continue
+			}
+			writeDelimited(ctx.Stdout, sep, opts, results[i], name)
+		}
+		if ok > 1 {
+			writeDelimited(ctx.Stdout, sep, opts, total, "total")
+		}
+	default:
+		for i, name := range names {
+			if errs[i] != nil {
+				continue
+			}
+			writeCounts(ctx.Stdout, width, opts, results[i], name)
+		}
+		if ok > 1 {
+			writeCounts(ctx.Stdout, width, opts, total, "total")
+		}
+	}
+	return failed
+}
+
+// countFiles counts every named file, in order, using up to parallel
+// goroutines. Each worker gets its own Counter (Counter.buf is a fixed
+// scratch buffer, so sharing one across goroutines would race), and
+// results land in the returned slices at the same index the name
+// appears in names, so the caller can print them in input order
+// regardless of which worker finished a given file first. writeCounts
+// itself is never called from here -- only the caller's goroutine
+// formats output, once every worker has joined.
+func countFiles(names []string, opts uint8, tabWidth int64, ambiguousWide bool, parallel int, decompress bool, mmapMode MMapMode) (results []Results, regular, compressed []bool, errs []error) {
+	results = make([]Results, len(names))
+	regular = make([]bool, len(names))
+	compressed = make([]bool, len(names))
+	errs = make([]error, len(names))
+
+	if len(names) == 0 {
+		return results, regular, compressed, errs
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > len(names) {
+		parallel = len(names)
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(parallel)
+	for i := 0; i < parallel; i++ {
+		go func() {
+			defer wg.Done()
+
+			ctr := NewCounter(opts)
+			ctr.TabWidth = tabWidth
+			ctr.AmbiguousWide = ambiguousWide
+			ctr.MMap = mmapMode
+
+			for idx := range indices {
+				res, isRegular, isCompressed, err := countFile(ctr, names[idx], decompress)
+				results[idx] = res
+				regular[idx] = isRegular
+				compressed[idx] = isCompressed
+				errs[idx] = err
+			}
+		}()
+	}
+	for i := range names {
+		indices <- i
 	}
-	if len(results) > 1 {
-		writeCounts(ctx.Stdout, width, opts, total, "total")
+	close(indices)
+	wg.Wait()
+
+	return results, regular, compressed, errs
+}
+
+// countFile opens fname and counts it with ctr. When decompress is
+// true, it first sniffs the file's magic bytes and, if they identify a
+// known compressed format, counts the decompressed stream instead --
+// matching `gzip -dc fname | wc`. Since the wrapped reader is no
+// longer an *os.File, Count's stat.Size()-based byte-only fast path
+// naturally doesn't fire for it, so the reported byte count is always
+// the decompressed one.
+func countFile(ctr *Counter, fname string, decompress bool) (res Results, regular, isCompressed bool, err error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return Results{}, false, false, err
+	}
+	defer file.Close()
+
+	if stat, statErr := file.Stat(); statErr == nil {
+		regular = stat.Mode().IsRegular()
+	}
+
+	var r io.Reader = file
+	if decompress {
+		format, peeked, err := sniffCompression(file)
+		if err != nil {
+			return Results{}, regular, false, err
+		}
+		r = peeked
+		if format != notCompressed {
+			dr, err := decompressStream(format, peeked)
+			if err != nil {
+				return Results{}, regular, false, err
+			}
+			if closer, ok := dr.(io.Closer); ok {
+				defer closer.Close()
+			}
+			r = dr
+			isCompressed = true
+		}
 	}
-	return nil
+
+	res, err = ctr.Count(r)
+	return res, regular, isCompressed, err
 }
 
 type sliceScanner struct{ s []string }
@@ -237,6 +452,10 @@ func writeCounts(w io.Writer, width int, opts uint8, r Results, fname string) {
 	}
 	if opts&MaxLength != 0 {
 		fmt.Fprintf(w, fmtInt, width, r.MaxLength)
+		fmtInt = fmtSpInt
+	}
+	if opts&Graphemes != 0 {
+		fmt.Fprintf(w, fmtInt, width, r.Graphemes)
 	}
 	fmt.Fprintf(w, " %s\n", fname)
 }
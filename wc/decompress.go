@@ -0,0 +1,74 @@
+package wc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressionFormat identifies a recognized compressed stream by its
+// leading magic bytes.
+type compressionFormat int
+
+const (
+	notCompressed compressionFormat = iota
+	formatGzip
+	formatBzip2
+	formatXz
+	formatZstd
+)
+
+var magicPrefixes = [...]struct {
+	format compressionFormat
+	magic  []byte
+}{
+	{formatGzip, []byte{0x1f, 0x8b}},
+	{formatBzip2, []byte("BZh")},
+	{formatXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{formatZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+const maxMagicLen = 6
+
+// sniffCompression peeks at the first few bytes of r to identify a
+// compressed format, if any, without consuming them: the returned
+// io.Reader yields the exact same bytes r would have, magic prefix
+// included, so it's always safe to use in r's place.
+func sniffCompression(r io.Reader) (compressionFormat, io.Reader, error) {
+	br := bufio.NewReaderSize(r, maxMagicLen)
+	peek, err := br.Peek(maxMagicLen)
+	if err != nil && err != io.EOF {
+		return notCompressed, br, err
+	}
+	for _, m := range magicPrefixes {
+		if bytes.HasPrefix(peek, m.magic) {
+			return m.format, br, nil
+		}
+	}
+	return notCompressed, br, nil
+}
+
+// decompressStream wraps r in a streaming decompressor for format.
+// format must not be notCompressed. If the returned io.Reader also
+// implements io.Closer, the caller is responsible for closing it once
+// it's done reading.
+func decompressStream(format compressionFormat, r io.Reader) (io.Reader, error) {
+	switch format {
+	case formatGzip:
+		return gzip.NewReader(r)
+	case formatBzip2:
+		return bzip2.NewReader(r), nil
+	case formatXz:
+		return xz.NewReader(r)
+	case formatZstd:
+		return zstd.NewReader(r)
+	default:
+		return nil, fmt.Errorf("wc: unsupported compression format %d", format)
+	}
+}
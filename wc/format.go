@@ -0,0 +1,151 @@
+package wc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	formatDefault = "default"
+	formatJSON    = "json"
+	formatNDJSON  = "ndjson"
+	formatCSV     = "csv"
+	formatTSV     = "tsv"
+)
+
+var errInvalidFormat = errors.New(`--format must be one of "default", "json", "ndjson", "csv", "tsv"`)
+
+func validFormat(format string) bool {
+	switch format {
+	case formatDefault, formatJSON, formatNDJSON, formatCSV, formatTSV:
+		return true
+	}
+	return false
+}
+
+// fieldNames returns the active result fields, in the fixed lines,
+// words, chars, bytes, max_line_length, grapheme_clusters order, that
+// opts (the same bitmask writeCounts switches on) selects.
+func fieldNames(opts uint8) []string {
+	var names []string
+	if opts&Lines != 0 {
+		names = append(names, "lines")
+	}
+	if opts&Words != 0 {
+		names = append(names, "words")
+	}
+	if opts&Chars != 0 {
+		names = append(names, "chars")
+	}
+	if opts&Bytes != 0 {
+		names = append(names, "bytes")
+	}
+	if opts&MaxLength != 0 {
+		names = append(names, "max_line_length")
+	}
+	if opts&Graphemes != 0 {
+		names = append(names, "grapheme_clusters")
+	}
+	return names
+}
+
+// fieldValues returns r's active fields in the same order fieldNames
+// returns them in.
+func fieldValues(opts uint8, r Results) []int64 {
+	var vals []int64
+	if opts&Lines != 0 {
+		vals = append(vals, r.Lines)
+	}
+	if opts&Words != 0 {
+		vals = append(vals, r.Words)
+	}
+	if opts&Chars != 0 {
+		vals = append(vals, r.Chars)
+	}
+	if opts&Bytes != 0 {
+		vals = append(vals, r.Bytes)
+	}
+	if opts&MaxLength != 0 {
+		vals = append(vals, r.MaxLength)
+	}
+	if opts&Graphemes != 0 {
+		vals = append(vals, r.Graphemes)
+	}
+	return vals
+}
+
+// writeDelimitedHeader writes the CSV/TSV header row for the fields
+// opts selects.
+func writeDelimitedHeader(w io.Writer, sep rune, opts uint8) {
+	fmt.Fprint(w, "name")
+	for _, name := range fieldNames(opts) {
+		fmt.Fprintf(w, "%c%s", sep, name)
+	}
+	fmt.Fprintln(w)
+}
+
+// writeDelimited writes one CSV/TSV record for r/name, quoting name
+// per RFC 4180 if it contains the separator, a double quote, or a
+// newline.
+func writeDelimited(w io.Writer, sep rune, opts uint8, r Results, name string) {
+	fmt.Fprint(w, quoteField(name, sep))
+	for _, v := range fieldValues(opts, r) {
+		fmt.Fprintf(w, "%c%d", sep, v)
+	}
+	fmt.Fprintln(w)
+}
+
+func quoteField(s string, sep rune) string {
+	if strings.ContainsRune(s, sep) || strings.ContainsAny(s, "\"\r\n") {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
+
+// writeNDJSON writes one self-contained JSON object for r/name,
+// terminated with a newline, so each line of output is independently
+// parseable -- unlike json mode, nothing needs to be buffered first.
+func writeNDJSON(w io.Writer, opts uint8, r Results, name string) {
+	nameJSON, _ := json.Marshal(name)
+	fmt.Fprintf(w, `{"name":%s`, nameJSON)
+	fields, values := fieldNames(opts), fieldValues(opts, r)
+	for i, field := range fields {
+		fmt.Fprintf(w, `,"%s":%d`, field, values[i])
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// writeJSON writes a single {"files":[...],"total":{...}} object
+// covering every file in names whose index is true in included, plus a
+// trailing total record. Unlike ndjson/csv/tsv, this can't be streamed
+// record-by-record, since the closing "]" and the total can't be
+// written until every file is known.
+func writeJSON(w io.Writer, opts uint8, names []string, results []Results, included []bool, total Results) {
+	fmt.Fprint(w, `{"files":[`)
+	first := true
+	for i, name := range names {
+		if !included[i] {
+			continue
+		}
+		if !first {
+			fmt.Fprint(w, ",")
+		}
+		first = false
+		nameJSON, _ := json.Marshal(name)
+		fmt.Fprintf(w, `{"name":%s`, nameJSON)
+		fields, values := fieldNames(opts), fieldValues(opts, results[i])
+		for j, field := range fields {
+			fmt.Fprintf(w, `,"%s":%d`, field, values[j])
+		}
+		fmt.Fprint(w, "}")
+	}
+	fmt.Fprint(w, `],"total":{"name":"total"`)
+	fields, values := fieldNames(opts), fieldValues(opts, total)
+	for j, field := range fields {
+		fmt.Fprintf(w, `,"%s":%d`, field, values[j])
+	}
+	fmt.Fprintln(w, "}}")
+}
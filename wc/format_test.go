@@ -0,0 +1,116 @@
+package wc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var opts uint8 = Lines | Words | Bytes
+	names := []string{"a.txt", "b.txt"}
+	results := []Results{
+		{Lines: 1, Words: 2, Bytes: 10},
+		{Lines: 3, Words: 4, Bytes: 20},
+	}
+	total := Results{Lines: 4, Words: 6, Bytes: 30}
+
+	var buf bytes.Buffer
+	writeJSON(&buf, opts, names, results, []bool{true, true}, total)
+
+	var doc struct {
+		Files []struct {
+			Name  string `json:"name"`
+			Lines int64  `json:"lines"`
+			Words int64  `json:"words"`
+			Bytes int64  `json:"bytes"`
+		} `json:"files"`
+		Total struct {
+			Name  string `json:"name"`
+			Lines int64  `json:"lines"`
+		} `json:"total"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("writeJSON produced invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(doc.Files) != 2 || doc.Files[0].Name != "a.txt" || doc.Files[0].Lines != 1 {
+		t.Errorf("files = %+v, want a.txt with Lines=1 first", doc.Files)
+	}
+	if doc.Total.Name != "total" || doc.Total.Lines != 4 {
+		t.Errorf("total = %+v, want name=total Lines=4", doc.Total)
+	}
+}
+
+func TestWriteJSONExcludesFailedFiles(t *testing.T) {
+	var opts uint8 = Lines
+	names := []string{"ok.txt", "bad.txt"}
+	results := []Results{{Lines: 1}, {}}
+	var buf bytes.Buffer
+	writeJSON(&buf, opts, names, results, []bool{true, false}, Results{Lines: 1})
+
+	var doc struct {
+		Files []struct {
+			Name string `json:"name"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(doc.Files) != 1 || doc.Files[0].Name != "ok.txt" {
+		t.Errorf("files = %+v, want only ok.txt", doc.Files)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var opts uint8 = Lines | Words
+	var buf bytes.Buffer
+	writeNDJSON(&buf, opts, Results{Lines: 1, Words: 2}, "f.txt")
+	writeNDJSON(&buf, opts, Results{Lines: 3, Words: 4}, "total")
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			t.Errorf("line %q is not valid standalone JSON: %v", line, err)
+		}
+	}
+}
+
+func TestWriteDelimitedQuoting(t *testing.T) {
+	var opts uint8 = Lines
+	var buf bytes.Buffer
+	writeDelimitedHeader(&buf, ',', opts)
+	writeDelimited(&buf, ',', opts, Results{Lines: 1}, `a,"b".txt`)
+
+	want := "name,lines\n\"a,\"\"b\"\".txt\",1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeDelimited output = %q, want %q", got, want)
+	}
+}
+
+func TestWriteDelimitedTSV(t *testing.T) {
+	var opts uint8 = Lines | Words
+	var buf bytes.Buffer
+	writeDelimitedHeader(&buf, '\t', opts)
+	writeDelimited(&buf, '\t', opts, Results{Lines: 1, Words: 2}, "f.txt")
+
+	want := "name\tlines\twords\nf.txt\t1\t2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeDelimited (tsv) output = %q, want %q", got, want)
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	for _, f := range []string{formatDefault, formatJSON, formatNDJSON, formatCSV, formatTSV} {
+		if !validFormat(f) {
+			t.Errorf("validFormat(%q) = false, want true", f)
+		}
+	}
+	if validFormat("yaml") {
+		t.Error(`validFormat("yaml") = true, want false`)
+	}
+}
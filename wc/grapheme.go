@@ -0,0 +1,170 @@
+package wc
+
+import "unicode"
+
+// graphemeClass is a deliberately scoped subset of the
+// Grapheme_Cluster_Break property values defined by UAX #29: enough to
+// implement GB3, GB4, GB5, GB6, GB7, GB8, GB9, GB9a, GB11, GB12/13, and
+// GB999. GB9b's Prepend class is omitted, since applying it needs a
+// rune of lookahead this streaming breaker doesn't keep.
+type graphemeClass uint8
+
+const (
+	gOther graphemeClass = iota
+	gCR
+	gLF
+	gControl
+	gExtend
+	gZWJ
+	gRegionalIndicator
+	gSpacingMark
+	gL
+	gV
+	gT
+	gLV
+	gLVT
+	gExtendedPictographic
+)
+
+func classifyGrapheme(r rune) graphemeClass {
+	switch r {
+	case '\r':
+		return gCR
+	case '\n':
+		return gLF
+	case 0x200D:
+		return gZWJ
+	}
+	if r >= 0x1F1E6 && r <= 0x1F1FF {
+		return gRegionalIndicator
+	}
+	if c, ok := hangulClass(r); ok {
+		return c
+	}
+	if isExtendedPictographic(r) {
+		return gExtendedPictographic
+	}
+	switch {
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r),
+		r == 0x200C,
+		r >= 0xFE00 && r <= 0xFE0F,
+		r >= 0x1F3FB && r <= 0x1F3FF,
+		r >= 0xE0020 && r <= 0xE007F:
+		return gExtend
+	case unicode.Is(unicode.Mc, r):
+		return gSpacingMark
+	case unicode.Is(unicode.Cc, r), unicode.Is(unicode.Cf, r),
+		unicode.Is(unicode.Zl, r), unicode.Is(unicode.Zp, r):
+		return gControl
+	}
+	return gOther
+}
+
+// hangulClass reports the Hangul Jamo class of r, if any: leading (L),
+// vowel (V), or trailing (T) Jamo, or a precomposed syllable block (LV
+// for one with no trailing Jamo, LVT otherwise).
+func hangulClass(r rune) (graphemeClass, bool) {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gL, true
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gV, true
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gT, true
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gLV, true
+		}
+		return gLVT, true
+	}
+	return gOther, false
+}
+
+// isExtendedPictographic approximates the Extended_Pictographic
+// property with the block ranges that cover almost all emoji in common
+// use, rather than the full derived property table.
+func isExtendedPictographic(r rune) bool {
+	switch {
+	case r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139,
+		r >= 0x2194 && r <= 0x21AA,
+		r >= 0x231A && r <= 0x231B,
+		r == 0x2328, r == 0x23CF,
+		r >= 0x23E9 && r <= 0x23FA,
+		r >= 0x25AA && r <= 0x25FE,
+		r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2934 && r <= 0x2935,
+		r >= 0x2B05 && r <= 0x2B07,
+		r >= 0x2B1B && r <= 0x2B1C,
+		r == 0x2B50, r == 0x2B55,
+		r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	}
+	return false
+}
+
+// graphemeBreaker counts UAX #29 grapheme clusters incrementally. Feed
+// it the runes of a stream in order with Push; Count holds the number
+// of user-perceived characters seen so far.
+type graphemeBreaker struct {
+	has             bool
+	prev            graphemeClass
+	riRun           int
+	pictographTrail bool
+	Count           int64
+}
+
+// Push feeds the next rune of the stream to the breaker.
+func (b *graphemeBreaker) Push(r rune) {
+	c := classifyGrapheme(r)
+
+	brk := true
+	if b.has {
+		switch {
+		case b.prev == gCR && c == gLF: // GB3
+			brk = false
+		case b.prev == gControl || b.prev == gCR || b.prev == gLF: // GB4
+			brk = true
+		case c == gControl || c == gCR || c == gLF: // GB5
+			brk = true
+		case b.prev == gL && (c == gL || c == gV || c == gLV || c == gLVT): // GB6
+			brk = false
+		case (b.prev == gLV || b.prev == gV) && (c == gV || c == gT): // GB7
+			brk = false
+		case (b.prev == gLVT || b.prev == gT) && c == gT: // GB8
+			brk = false
+		case c == gExtend || c == gZWJ: // GB9
+			brk = false
+		case c == gSpacingMark: // GB9a
+			brk = false
+		case b.prev == gZWJ && c == gExtendedPictographic && b.pictographTrail: // GB11
+			brk = false
+		case b.prev == gRegionalIndicator && c == gRegionalIndicator && b.riRun%2 == 1: // GB12/13
+			brk = false
+		default: // GB999
+			brk = true
+		}
+	}
+
+	if brk {
+		b.Count++
+	}
+
+	switch c {
+	case gExtendedPictographic:
+		b.pictographTrail = true
+	case gExtend, gZWJ:
+		// An Extend or ZWJ right after a pictograph keeps GB11's
+		// lookahead armed; anything else (below) disarms it.
+	default:
+		b.pictographTrail = false
+	}
+
+	if c == gRegionalIndicator {
+		b.riRun++
+	} else {
+		b.riRun = 0
+	}
+
+	b.prev = c
+	b.has = true
+}
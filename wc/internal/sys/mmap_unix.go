@@ -0,0 +1,15 @@
+// +build linux freebsd
+
+package sys
+
+import "golang.org/x/sys/unix"
+
+// Mmap maps the first size bytes of fd into memory, read-only.
+func Mmap(fd int, size int) ([]byte, error) {
+	return unix.Mmap(fd, 0, size, unix.PROT_READ, unix.MAP_SHARED)
+}
+
+// Munmap unmaps a mapping returned by Mmap.
+func Munmap(b []byte) error {
+	return unix.Munmap(b)
+}
@@ -5,13 +5,13 @@ package main
 import (
 	"os"
 
-	"github.com/ericlagergren/go-coreutils/coreutils"
+	coreutils "github.com/ericlagergren/go-coreutils"
 
 	_ "github.com/ericlagergren/go-coreutils/wc"
 )
 
 func main() {
-	ctx := coreutils.Ctx{
+	ctx := coreutils.Context{
 		Stdin:  os.Stdin,
 		Stdout: os.Stdout,
 		Stderr: os.Stderr,
@@ -0,0 +1,88 @@
+package wc
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/ericlagergren/go-coreutils/wc/internal/sys"
+)
+
+var errInvalidMMap = errors.New(`--mmap must be one of "auto", "always", "never"`)
+
+// MMapMode controls whether Count maps a regular *os.File into memory
+// instead of reading it through Counter's streaming buffer.
+type MMapMode uint8
+
+const (
+	MMapAuto   MMapMode = iota // map files at or above mmapThreshold
+	MMapAlways                 // map every regular file, regardless of size
+	MMapNever                  // never map; always use the streaming read path
+)
+
+// mmapThreshold is the file size, in bytes, at or above which MMapAuto
+// maps a regular file instead of streaming it.
+const mmapThreshold = 2 << 20 // 2 MiB
+
+// ParseMMapMode parses the --mmap flag's value.
+func ParseMMapMode(s string) (MMapMode, bool) {
+	switch s {
+	case "auto":
+		return MMapAuto, true
+	case "always":
+		return MMapAlways, true
+	case "never":
+		return MMapNever, true
+	}
+	return 0, false
+}
+
+// tryMmap maps file into memory if c.MMap and file's size call for it.
+// Any failure to set up the mapping -- file isn't regular, is empty, is
+// too large for the address space, or lives on a filesystem that
+// doesn't support mmap -- is reported as ok == false so the caller can
+// silently fall back to the streaming read path, never as an error.
+// A file whose size changes after it has been mapped is not detected:
+// growth past the mapped size is not counted, and reading pages lost
+// to truncation raises SIGBUS.
+func (c *Counter) tryMmap(file *os.File) (data []byte, ok bool) {
+	stat, err := file.Stat()
+	if err != nil || !stat.Mode().IsRegular() {
+		return nil, false
+	}
+
+	size := stat.Size()
+	if size <= 0 || int64(int(size)) != size {
+		return nil, false // empty, or too large to fit in an int (32-bit).
+	}
+	if c.MMap == MMapAuto && size < mmapThreshold {
+		return nil, false
+	}
+
+	data, err = sys.Mmap(int(file.Fd()), int(size))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// countMapped runs Count's byte/line/complicated logic directly against
+// an mmap'd file's contents, skipping the read-into-buf copy entirely.
+func (c *Counter) countMapped(data []byte) (res Results, err error) {
+	switch c.opts {
+	case Bytes:
+		res.Bytes = int64(len(data))
+	case Lines, Lines | Bytes:
+		res.Bytes = int64(len(data))
+		res.Lines = int64(bytes.Count(data, newLine))
+	default:
+		useWordsUAX29 := c.opts&WordsUAX29 != 0
+		useGraphemes := c.opts&Graphemes != 0
+
+		var st countState
+		res.Bytes = int64(len(data))
+		c.processChunk(data, &st, useWordsUAX29, useGraphemes, &res)
+		st.finish(&res, useWordsUAX29, useGraphemes)
+	}
+	return res, nil
+}
@@ -0,0 +1,90 @@
+package wc
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCounterMMapAlwaysMatchesStreaming(t *testing.T) {
+	content := strings.Repeat("hello world\nfoo bar baz\n", 100)
+
+	f, err := os.CreateTemp("", "wc-mmap-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var opts uint8 = Lines | Words | Chars | Bytes | MaxLength
+	streamed := NewCounter(opts)
+	streamed.MMap = MMapNever
+	wantRes, err := streamed.Count(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	mapped := NewCounter(opts)
+	mapped.MMap = MMapAlways
+	gotRes, err := mapped.Count(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotRes != wantRes {
+		t.Errorf("mmap Count = %+v, want %+v", gotRes, wantRes)
+	}
+}
+
+// TestCounterMMapAutoSkipsSmallFiles counts a file far below
+// mmapThreshold under the default MMapAuto and checks the counts; it
+// does not observe whether the file was mapped or streamed.
+func TestCounterMMapAutoSkipsSmallFiles(t *testing.T) {
+	f, err := os.CreateTemp("", "wc-mmap-small-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("tiny\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCounter(Lines | Words | Bytes)
+	res, err := c.Count(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Lines != 1 || res.Words != 1 {
+		t.Errorf("res = %+v, want Lines=1 Words=1", res)
+	}
+}
+
+func TestParseMMapMode(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want MMapMode
+	}{
+		{"auto", MMapAuto},
+		{"always", MMapAlways},
+		{"never", MMapNever},
+	} {
+		got, ok := ParseMMapMode(tc.s)
+		if !ok || got != tc.want {
+			t.Errorf("ParseMMapMode(%q) = (%v, %v), want (%v, true)", tc.s, got, ok, tc.want)
+		}
+	}
+	if _, ok := ParseMMapMode("sometimes"); ok {
+		t.Error(`ParseMMapMode("sometimes") = ok, want !ok`)
+	}
+}
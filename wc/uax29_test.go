@@ -0,0 +1,147 @@
+package wc
+
+import (
+	"strings"
+	"testing"
+)
+
+func countGraphemes(s string) int64 {
+	var gb graphemeBreaker
+	for _, r := range s {
+		gb.Push(r)
+	}
+	return gb.Count
+}
+
+func TestGraphemeBreakerRegionalIndicatorPair(t *testing.T) {
+	// U+1F1FA U+1F1F8 is the flag of the United States: two Regional
+	// Indicator runes that GB12/13 require to stay in one cluster.
+	const flag = "\U0001F1FA\U0001F1F8"
+	if got, want := countGraphemes(flag), int64(1); got != want {
+		t.Errorf("countGraphemes(%q) = %d, want %d", flag, got, want)
+	}
+
+	// Four Regional Indicators form two flags, i.e. two clusters.
+	const twoFlags = flag + flag
+	if got, want := countGraphemes(twoFlags), int64(2); got != want {
+		t.Errorf("countGraphemes(%q) = %d, want %d", twoFlags, got, want)
+	}
+}
+
+func TestGraphemeBreakerFamilyZWJSequence(t *testing.T) {
+	// MAN, ZWJ, WOMAN, ZWJ, GIRL, ZWJ, BOY: a single "family" emoji
+	// sequence joined entirely by GB11.
+	const family = "\U0001F468‍\U0001F469‍\U0001F467‍\U0001F466"
+	if got, want := countGraphemes(family), int64(1); got != want {
+		t.Errorf("countGraphemes(%q) = %d, want %d", family, got, want)
+	}
+}
+
+func TestGraphemeBreakerDevanagariCluster(t *testing.T) {
+	// U+0915 KA followed by U+094D VIRAMA: GB9 merges the Virama (Mn,
+	// Extend) into the preceding base letter's cluster, but the
+	// following U+0937 SSA is itself a base letter and starts a new
+	// cluster -- "क्ष" is two grapheme clusters ("क्" and "ष"), not one,
+	// matching every standard UAX #29 grapheme splitter.
+	const ksha = "क्ष"
+	if got, want := countGraphemes(ksha), int64(2); got != want {
+		t.Errorf("countGraphemes(%q) = %d, want %d", ksha, got, want)
+	}
+
+	const three = ksha + "क"
+	if got, want := countGraphemes(three), int64(3); got != want {
+		t.Errorf("countGraphemes(%q) = %d, want %d", three, got, want)
+	}
+}
+
+func TestCounterMaxLengthTabExpandedCJK(t *testing.T) {
+	// A tab (expanding to 8 columns) followed by 3 CJK ideographs (2
+	// columns each) should report a max line length of 8+6 = 14.
+	c := NewCounter(MaxLength)
+	line := "\t" + strings.Repeat("中", 3) + "\n"
+	res, err := c.Count(strings.NewReader(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.MaxLength, int64(14); got != want {
+		t.Errorf("MaxLength = %d, want %d", got, want)
+	}
+}
+
+func TestCounterGraphemesOption(t *testing.T) {
+	c := NewCounter(Graphemes)
+	const family = "\U0001F468‍\U0001F469‍\U0001F467"
+	res, err := c.Count(strings.NewReader(family))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Graphemes, int64(1); got != want {
+		t.Errorf("Graphemes = %d, want %d", got, want)
+	}
+}
+
+func TestCounterWordsUAX29(t *testing.T) {
+	c := NewCounter(WordsUAX29)
+	res, err := c.Count(strings.NewReader("hello world\n42 apples\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(4); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+}
+
+func TestCounterWordsUAX29Contraction(t *testing.T) {
+	// WB6/WB7: an apostrophe flanked by letters on both sides (a
+	// Single_Quote/MidNumLet rune in UAX #29 terms) doesn't split
+	// "don't" into two words.
+	c := NewCounter(WordsUAX29)
+	res, err := c.Count(strings.NewReader("don't stop\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(2); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+}
+
+func TestCounterWordsUAX29DecimalNumber(t *testing.T) {
+	// WB11/WB12: a period flanked by digits on both sides keeps
+	// "3.14" as a single numeric run instead of splitting on the dot.
+	c := NewCounter(WordsUAX29)
+	res, err := c.Count(strings.NewReader("pi is 3.14 today\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(4); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+}
+
+func TestCounterWordsUAX29MidPunctuationDoesNotBridgeUnlike(t *testing.T) {
+	// A period between a letter and a digit isn't MidNumLet bridging
+	// anything -- WB6/WB7 and WB11/WB12 both require the same class on
+	// both sides, so "abc.123" is two words, not one.
+	c := NewCounter(WordsUAX29)
+	res, err := c.Count(strings.NewReader("abc.123\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(2); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+}
+
+func TestCounterWordsUAX29TrailingMidPunctuation(t *testing.T) {
+	// A sentence-ending period has nothing after it to bridge with. It
+	// is still held back as pending when the input ends, and since a
+	// held-back rune never adds a word, the count stays at 2.
+	c := NewCounter(WordsUAX29)
+	res, err := c.Count(strings.NewReader("That's all."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := res.Words, int64(2); got != want {
+		t.Errorf("Words = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package wc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandNames replaces each directory operand in names with the
+// regular files found beneath it, in the order they're discovered,
+// when recursive is true. Plain file operands, and directory operands
+// when recursive is false, pass through unchanged -- countFile is left
+// to report the "is a directory" error countFile/os.Open already
+// produces for that case.
+func expandNames(names []string, recursive bool, includes, excludes []string, hidden, followSymlinks bool) ([]string, error) {
+	if !recursive {
+		return names, nil
+	}
+
+	out := make([]string, 0, len(names))
+	visited := make(map[string]bool)
+	for _, name := range names {
+		info, err := os.Lstat(name)
+		if err != nil {
+			out = append(out, name) // let countFile report the real error.
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !followSymlinks {
+				out = append(out, name)
+				continue
+			}
+			if info, err = os.Stat(name); err != nil {
+				out = append(out, name)
+				continue
+			}
+		}
+		if !info.IsDir() {
+			out = append(out, name)
+			continue
+		}
+
+		out, err = walkDir(name, out, includes, excludes, hidden, followSymlinks, visited)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// walkDir appends every matching regular file beneath dir to out,
+// recursing into subdirectories (and, when followSymlinks is true,
+// into directories reached through a symlink). visited guards against
+// symlink cycles by tracking each directory's resolved real path; it's
+// only consulted when followSymlinks is true, since a plain recursive
+// walk of real directories can't cycle.
+func walkDir(dir string, out []string, includes, excludes []string, hidden, followSymlinks bool, visited map[string]bool) ([]string, error) {
+	if followSymlinks {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			real = dir
+		}
+		if visited[real] {
+			return out, nil
+		}
+		visited[real] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return out, err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !hidden && isHidden(name) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+
+		typ := e.Type()
+		if typ&os.ModeSymlink != 0 {
+			if !followSymlinks {
+				continue
+			}
+			target, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if target.IsDir() {
+				if out, err = walkDir(path, out, includes, excludes, hidden, followSymlinks, visited); err != nil {
+					return out, err
+				}
+				continue
+			}
+			if target.Mode().IsRegular() && matchesFilters(name, includes, excludes) {
+				out = append(out, path)
+			}
+			continue
+		}
+
+		if e.IsDir() {
+			var err error
+			if out, err = walkDir(path, out, includes, excludes, hidden, followSymlinks, visited); err != nil {
+				return out, err
+			}
+			continue
+		}
+
+		if e.Type().IsRegular() && matchesFilters(name, includes, excludes) {
+			out = append(out, path)
+		}
+	}
+	return out, nil
+}
+
+func isHidden(base string) bool {
+	return strings.HasPrefix(base, ".") && base != "." && base != ".."
+}
+
+// matchesFilters reports whether base should be counted: it must match
+// at least one --include glob (if any were given), and must not match
+// any --exclude glob, which is applied after includes.
+func matchesFilters(base string, includes, excludes []string) bool {
+	if len(includes) > 0 {
+		matched := false
+		for _, pat := range includes {
+			if ok, _ := filepath.Match(pat, base); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, pat := range excludes {
+		if ok, _ := filepath.Match(pat, base); ok {
+			return false
+		}
+	}
+	return true
+}
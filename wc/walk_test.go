@@ -0,0 +1,125 @@
+package wc
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpandNamesNonRecursivePassesThrough(t *testing.T) {
+	dir := t.TempDir()
+	got, err := expandNames([]string{dir}, false, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != dir {
+		t.Errorf("expandNames(non-recursive) = %v, want [%s] unchanged", got, dir)
+	}
+}
+
+func TestExpandNamesRecursiveSkipsHidden(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "a.txt"), "a")
+	mustWriteFile(t, filepath.Join(dir, ".hidden.txt"), "b")
+	mustWriteFile(t, filepath.Join(dir, ".git", "config"), "c")
+	mustWriteFile(t, filepath.Join(dir, "sub", "b.txt"), "d")
+
+	got, err := expandNames([]string{dir}, true, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "sub", "b.txt")}
+	sort.Strings(want)
+	if !equalStrings(got, want) {
+		t.Errorf("expandNames = %v, want %v", got, want)
+	}
+}
+
+func TestExpandNamesRecursiveIncludesHiddenWhenAsked(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "a.txt"), "a")
+	mustWriteFile(t, filepath.Join(dir, ".hidden.txt"), "b")
+
+	got, err := expandNames([]string{dir}, true, nil, nil, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expandNames(hidden=true) = %v, want 2 entries", got)
+	}
+}
+
+func TestExpandNamesIncludeExclude(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "a.go"), "a")
+	mustWriteFile(t, filepath.Join(dir, "b.txt"), "b")
+	mustWriteFile(t, filepath.Join(dir, "a_test.go"), "c")
+
+	got, err := expandNames([]string{dir}, true, []string{"*.go"}, []string{"*_test.go"}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.go")}
+	if !equalStrings(got, want) {
+		t.Errorf("expandNames(include/exclude) = %v, want %v", got, want)
+	}
+}
+
+func TestExpandNamesSkipsSymlinksByDefault(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "real.txt"), "a")
+	if err := os.Symlink(filepath.Join(dir, "real.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	got, err := expandNames([]string{dir}, true, nil, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "real.txt")}
+	if !equalStrings(got, want) {
+		t.Errorf("expandNames(follow-symlinks=false) = %v, want %v", got, want)
+	}
+}
+
+func TestExpandNamesFollowsSymlinksWhenAsked(t *testing.T) {
+	dir := t.TempDir()
+	mustWriteFile(t, filepath.Join(dir, "real.txt"), "a")
+	if err := os.Symlink(filepath.Join(dir, "real.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	got, err := expandNames([]string{dir}, true, nil, nil, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expandNames(follow-symlinks=true) = %v, want 2 entries", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -16,33 +16,47 @@ type Results struct {
 	Chars     int64
 	Bytes     int64
 	MaxLength int64
+	Graphemes int64
 }
 
 type Counter struct {
 	TabWidth int64
 
+	// AmbiguousWide treats East Asian Ambiguous-width runes as 2
+	// columns wide in the MaxLength calculation, instead of 1.
+	AmbiguousWide bool
+
+	// MMap controls whether a regular *os.File passed to Count is
+	// mapped into memory instead of read through buf. See MMapMode.
+	MMap MMapMode
+
 	buf  [1 << 17]byte
 	opts uint8
 }
 
 const (
-	Lines     = 1 << iota // count lines
-	Words                 // count words
-	Chars                 // count chars
-	Bytes                 // count bytes
-	MaxLength             // find max line length
+	Lines      = 1 << iota // count lines
+	Words                  // count words
+	Chars                  // count chars
+	Bytes                  // count bytes
+	MaxLength              // find max line length
+	Graphemes              // count UAX #29 grapheme clusters
+	WordsUAX29             // count words using UAX #29 word boundaries
 )
 
 func NewCounter(opts uint8) *Counter {
 	return &Counter{opts: opts, TabWidth: 8}
 }
 
+// read fills c.buf and returns how much of it holds valid data. Per
+// the io.Reader contract, a Read that hits EOF may still report n > 0
+// in the same call -- bufio-backed readers rarely do, but decompressing
+// ones (e.g. compress/gzip) routinely return their last chunk and EOF
+// together, so callers must use n before checking err, never instead
+// of it.
 func (c *Counter) read(r io.Reader) (int64, error) {
 	n, err := r.Read(c.buf[:])
-	if err != nil {
-		return 0, err
-	}
-	return int64(n), nil
+	return int64(n), err
 }
 
 var newLine = []byte{'\n'}
@@ -55,6 +69,13 @@ func (c *Counter) Count(r io.Reader) (res Results, err error) {
 			}
 		}
 		sys.Fadvise(int(file.Fd()))
+
+		if c.MMap != MMapNever {
+			if data, ok := c.tryMmap(file); ok {
+				defer sys.Munmap(data)
+				return c.countMapped(data)
+			}
+		}
 	}
 	switch c.opts {
 	case Bytes:
@@ -85,23 +106,138 @@ func (c *Counter) Count(r io.Reader) (res Results, err error) {
 	}
 }
 
+// countState carries the position-in-line and in-progress-word/grapheme
+// state that processChunk threads across however many chunks Count
+// feeds it -- one call per streaming read, or a single call covering an
+// entire mmap'd file.
+type countState struct {
+	pos    int64
+	inword int64
+	wb     wordBreaker
+	gb     graphemeBreaker
+}
+
+// finish folds any still-open line/word/grapheme state into res, once
+// the caller has seen every chunk there is.
+func (st *countState) finish(res *Results, useWordsUAX29, useGraphemes bool) {
+	if st.pos > res.MaxLength {
+		res.MaxLength = st.pos
+	}
+	if useWordsUAX29 {
+		res.Words = st.wb.Words
+	} else {
+		res.Words += st.inword
+	}
+	if useGraphemes {
+		res.Graphemes = st.gb.Count
+	}
+}
+
+// countComplicated is the slow path for Count: it's reached whenever
+// Counter needs anything beyond a raw byte or line count, which means
+// decoding the input rune by rune. As an invariant, the ASCII fast
+// path below and the utf8.DecodeRune loop that follows it must produce
+// bit-identical Results for ASCII input -- the fast path is purely an
+// optimization, never a change in what gets counted.
 func (c *Counter) countComplicated(r io.Reader) (res Results, err error) {
-	var (
-		pos    int64
-		inword int64
-	)
+	useWordsUAX29 := c.opts&WordsUAX29 != 0
+	useGraphemes := c.opts&Graphemes != 0
+
+	var st countState
+
 	for {
 		n, err := c.read(r)
 		res.Bytes += n
+
+		// Process whatever n bytes came back before acting on err: per
+		// the io.Reader contract, a reader that just hit EOF may still
+		// have returned n > 0 in this same call (compress/gzip and
+		// friends routinely do), and dropping that last chunk would
+		// silently undercount.
+		c.processChunk(c.buf[:n], &st, useWordsUAX29, useGraphemes, &res)
+
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 			return res, err
 		}
+	}
+	st.finish(&res, useWordsUAX29, useGraphemes)
+	return res, nil
+}
+
+// processChunk runs the ASCII-fast-path/UTF-8-decode counting loop over
+// chunk, threading pos/inword/word-and-grapheme-breaker state through
+// st so it can be called once per streaming read or once for an entire
+// mmap'd file.
+func (c *Counter) processChunk(chunk []byte, st *countState, useWordsUAX29, useGraphemes bool, res *Results) {
+	pos, inword := st.pos, st.inword
+	wb, gb := &st.wb, &st.gb
+
+	if isASCII(chunk) {
+		// Every byte in this chunk decodes to itself as a rune, so
+		// skip utf8.DecodeRune and unicode.IsPrint/IsSpace entirely
+		// and drive the same state machine off a table lookup. If
+		// even one byte in the chunk were non-ASCII we'd have to
+		// fall back to the decode loop for the whole chunk anyway
+		// (a multi-byte rune could start anywhere in it), so the
+		// isASCII pre-scan above re-scans from the start rather
+		// than bailing out of this loop partway through.
+		for _, ch := range chunk {
+			if useGraphemes {
+				gb.Push(rune(ch))
+			}
+			if useWordsUAX29 {
+				wb.Push(rune(ch))
+			}
+
+			switch asciiClassTable[ch] {
+			case asciiLF:
+				res.Lines++
+				fallthrough
+			case asciiCRFF:
+				if pos > res.MaxLength {
+					res.MaxLength = pos
+				}
+				pos = 0
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
+			case asciiTab:
+				pos += c.TabWidth - (pos % c.TabWidth)
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
+			case asciiSpace:
+				pos++
+				fallthrough
+			case asciiVTab:
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
+			case asciiPrint:
+				pos++
+				if !useWordsUAX29 {
+					inword = 1
+				}
+			}
+			res.Chars++
+		}
+	} else {
+		for bp := 0; bp < len(chunk); {
+			r, s := utf8.DecodeRune(chunk[bp:])
+
+			if useGraphemes {
+				gb.Push(r)
+			}
+			if useWordsUAX29 {
+				wb.Push(r)
+			}
 
-		for bp := 0; int64(bp) < n; {
-			r, s := utf8.DecodeRune(c.buf[bp:])
 			switch r {
 			case '\n':
 				res.Lines++
@@ -113,40 +249,45 @@ func (c *Counter) countComplicated(r io.Reader) (res Results, err error) {
 					res.MaxLength = pos
 				}
 				pos = 0
-				res.Words += inword
-				inword = 0
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
 			case '\t':
 				pos += c.TabWidth - (pos % c.TabWidth)
-				res.Words += inword
-				inword = 0
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
 			case ' ':
 				pos++
 				fallthrough
 			case '\v':
-				res.Words += inword
-				inword = 0
+				if !useWordsUAX29 {
+					res.Words += inword
+					inword = 0
+				}
 			default:
 				if !unicode.IsPrint(r) {
 					break
 				}
 
-				pos++
-				if unicode.IsSpace(r) {
-					res.Words += inword
-					inword = 0
-				} else {
-					inword = 1
+				pos += int64(DisplayWidth(r, c.AmbiguousWide))
+				if !useWordsUAX29 {
+					if unicode.IsSpace(r) {
+						res.Words += inword
+						inword = 0
+					} else {
+						inword = 1
+					}
 				}
 			}
 			res.Chars++
 			bp += s
 		}
 	}
-	if pos > res.MaxLength {
-		res.MaxLength = pos
-	}
-	res.Words += inword
-	return res, nil
+
+	st.pos, st.inword = pos, inword
 }
 
 func statSize(file *os.File) (n int64, ok bool) {
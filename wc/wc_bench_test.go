@@ -0,0 +1,61 @@
+package wc
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// benchFiles writes a handful of small files and a few larger ones to a
+// scratch directory, returning their paths -- small files stress the
+// worker pool's per-file overhead, large ones stress a single Counter's
+// throughput, matching the "many small files" / "a few large files"
+// workloads countFiles is meant to help with.
+func benchFiles(b *testing.B) []string {
+	dir := b.TempDir()
+	rng := rand.New(rand.NewSource(1))
+
+	var names []string
+	small := make([]byte, 4*1024)
+	rng.Read(small)
+	for i := 0; i < 64; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("small%d.txt", i))
+		if err := os.WriteFile(fn, small, 0644); err != nil {
+			b.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	large := make([]byte, 4*1024*1024)
+	rng.Read(large)
+	for i := 0; i < 4; i++ {
+		fn := filepath.Join(dir, fmt.Sprintf("large%d.txt", i))
+		if err := os.WriteFile(fn, large, 0644); err != nil {
+			b.Fatal(err)
+		}
+		names = append(names, fn)
+	}
+
+	return names
+}
+
+func BenchmarkCountFilesSerial(b *testing.B) {
+	names := benchFiles(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		countFiles(names, Lines|Words|Bytes, 8, false, 1, false, MMapNever)
+	}
+}
+
+func BenchmarkCountFilesParallel(b *testing.B) {
+	names := benchFiles(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		countFiles(names, Lines|Words|Bytes, 8, false, runtime.NumCPU(), false, MMapNever)
+	}
+}
@@ -0,0 +1,50 @@
+package wc
+
+import (
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// UAX29Revision identifies the revision of UAX #29 (Unicode Text
+// Segmentation) implemented by graphemeBreaker and wordBreaker. UAX #29
+// revisions are published alongside, and numbered after, the version of
+// the Unicode Standard they accompany, so unicode.Version doubles as
+// the source of truth for both.
+const UAX29Revision = unicode.Version
+
+// DisplayWidth returns the number of terminal columns a single rune
+// occupies. Combining marks, zero-width joiners, and other runes with
+// no visual presence of their own return 0. East Asian Wide and
+// Fullwidth runes return 2. East Asian Ambiguous runes return 2 if
+// ambiguousWide is true, 1 otherwise.
+func DisplayWidth(r rune, ambiguousWide bool) int {
+	if isZeroWidth(r) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	case width.EastAsianAmbiguous:
+		if ambiguousWide {
+			return 2
+		}
+		return 1
+	}
+	return 1
+}
+
+// isZeroWidth reports whether r is a combining mark, a zero-width
+// joiner/non-joiner, a variation selector, an emoji skin-tone modifier,
+// or a tag character -- runes that attach to the preceding grapheme
+// cluster without adding to its display width.
+func isZeroWidth(r rune) bool {
+	switch {
+	case r == 0x200C, r == 0x200D, // ZWNJ, ZWJ
+		r >= 0xFE00 && r <= 0xFE0F,   // variation selectors
+		r >= 0x1F3FB && r <= 0x1F3FF, // emoji skin-tone modifiers
+		r >= 0xE0020 && r <= 0xE007F: // tag characters
+		return true
+	}
+	return unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r)
+}
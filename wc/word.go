@@ -0,0 +1,203 @@
+package wc
+
+import "unicode"
+
+// wordClass is a deliberately scoped subset of the Word_Break property
+// values defined by UAX #29, covering WB3, WB3a, WB3b, WB4, WB5, WB6,
+// WB7, WB8, WB9, WB10, WB11, WB12, WB13a, WB13b, and the
+// regional-indicator pairing rule. The MidLetter/MidNum/MidNumLet/
+// Single_Quote code points recognized here are a practical, ASCII-
+// leaning subset of the full Word_Break property table (the complete
+// list lives in auxiliary/WordBreakProperty.txt and covers a long tail
+// of scripts this breaker doesn't otherwise support -- see
+// isWordLetter), the same kind of deliberate scoping grapheme.go uses
+// for GB9b's Prepend.
+type wordClass uint8
+
+const (
+	wOther wordClass = iota
+	wCR
+	wLF
+	wNewline
+	wExtend
+	wALetter
+	wNumeric
+	wExtendNumLet
+	wRegionalIndicator
+	wMidLetterOnly // WB6/7 only: ':', middle dot, hyphenation point
+	wMidNumOnly    // WB11/12 only: ',', ';'
+	wMidBoth       // WB6/7 and WB11/12: MidNumLet + Single_Quote, e.g. '.', '\''
+)
+
+func classifyWord(r rune) wordClass {
+	switch r {
+	case '\r':
+		return wCR
+	case '\n':
+		return wLF
+	case 0x0B, 0x0C, 0x85, 0x2028, 0x2029:
+		return wNewline
+	case '_':
+		return wExtendNumLet
+	case ':', 0x00B7, 0x2027:
+		return wMidLetterOnly
+	case ',', ';':
+		return wMidNumOnly
+	case '.', '\'', 0x2019:
+		return wMidBoth
+	}
+	if r >= 0x1F1E6 && r <= 0x1F1FF {
+		return wRegionalIndicator
+	}
+	switch {
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r),
+		unicode.Is(unicode.Cf, r), r == 0x200C, r == 0x200D:
+		return wExtend
+	case unicode.IsDigit(r):
+		return wNumeric
+	case isWordLetter(r):
+		return wALetter
+	}
+	return wOther
+}
+
+// isWordLetter reports whether r is an ALetter for word-breaking
+// purposes: a letter outside the scripts UAX #29 itself excludes from
+// ALetter because they need dictionary-based segmentation (CJK
+// ideographs and kana, Thai, Lao, Khmer, Myanmar). Those fall back to
+// Other, so WB999 breaks between every pair of them -- the correct,
+// un-dictionaried UAX #29 behavior, not a shortcut.
+func isWordLetter(r rune) bool {
+	if !unicode.IsLetter(r) {
+		return false
+	}
+	switch {
+	case unicode.Is(unicode.Han, r),
+		unicode.Is(unicode.Hiragana, r),
+		unicode.Is(unicode.Katakana, r),
+		unicode.Is(unicode.Thai, r),
+		unicode.Is(unicode.Lao, r),
+		unicode.Is(unicode.Khmer, r),
+		unicode.Is(unicode.Myanmar, r):
+		return false
+	}
+	return true
+}
+
+// midCompatible reports whether a Mid-category rune of class mid is
+// even eligible to bridge a run of class ctx -- i.e. whether WB6/WB11
+// could possibly apply before we've seen the rune after it.
+func midCompatible(mid, ctx wordClass) bool {
+	switch mid {
+	case wMidBoth:
+		return ctx == wALetter || ctx == wNumeric
+	case wMidLetterOnly:
+		return ctx == wALetter
+	case wMidNumOnly:
+		return ctx == wNumeric
+	}
+	return false
+}
+
+// wordBreaker counts UAX #29 word-boundary runs incrementally: Words
+// accumulates once per run that starts with an ALetter, Numeric,
+// ExtendNumLet, or a paired Regional_Indicator, i.e. a run wc treats as
+// a "word".
+type wordBreaker struct {
+	has   bool
+	prev  wordClass
+	riRun int
+	Words int64
+
+	// pending holds back a Mid-category rune (WB6/WB7's MidLetter and
+	// WB11/WB12's MidNum, MidNumLet, Single_Quote) until the rune after
+	// it arrives: "don't" and "3.14" only keep their run intact if the
+	// same class of rune appears on both sides of the mid character,
+	// which isn't knowable until that next rune shows up.
+	pending  bool
+	midClass wordClass
+	midCtx   wordClass
+}
+
+func isWordy(c wordClass) bool {
+	switch c {
+	case wALetter, wNumeric, wExtendNumLet, wRegionalIndicator:
+		return true
+	}
+	return false
+}
+
+// Push feeds the next rune of the stream to the breaker.
+func (b *wordBreaker) Push(r rune) {
+	c := classifyWord(r)
+
+	if b.pending {
+		ctx := b.midCtx
+		b.pending = false
+		if c == ctx { // WB6/WB7 or WB11/WB12: mid bridges two like runs.
+			b.prev = c
+			b.riRun = 0
+			return
+		}
+		// The bridge didn't hold, so mid never merged anything -- it's
+		// a plain, non-wordy Other rune between the run that came
+		// before it and whatever c turns out to be.
+		b.commit(wOther)
+	}
+
+	if midCompatible(c, b.prev) && b.has {
+		b.midClass, b.midCtx = c, b.prev
+		b.pending = true
+		return
+	}
+
+	b.commit(c)
+}
+
+// commit runs the non-lookahead WB3-WB13b/regional-indicator rules for
+// class c against the breaker's current state.
+func (b *wordBreaker) commit(c wordClass) {
+	if c == wExtend && b.has {
+		return // WB4: folds into the preceding class, no boundary.
+	}
+
+	brk := true
+	if b.has {
+		switch {
+		case b.prev == wCR && c == wLF: // WB3
+			brk = false
+		case b.prev == wCR || b.prev == wLF || b.prev == wNewline: // WB3a
+			brk = true
+		case c == wCR || c == wLF || c == wNewline: // WB3b
+			brk = true
+		case b.prev == wALetter && c == wALetter: // WB5
+			brk = false
+		case b.prev == wNumeric && c == wNumeric: // WB8
+			brk = false
+		case b.prev == wALetter && c == wNumeric: // WB9
+			brk = false
+		case b.prev == wNumeric && c == wALetter: // WB10
+			brk = false
+		case (b.prev == wALetter || b.prev == wNumeric || b.prev == wExtendNumLet) && c == wExtendNumLet: // WB13a
+			brk = false
+		case b.prev == wExtendNumLet && (c == wALetter || c == wNumeric): // WB13b
+			brk = false
+		case b.prev == wRegionalIndicator && c == wRegionalIndicator && b.riRun%2 == 1: // regional-indicator pairing
+			brk = false
+		default: // WB999
+			brk = true
+		}
+	}
+
+	if brk && isWordy(c) {
+		b.Words++
+	}
+
+	if c == wRegionalIndicator {
+		b.riRun++
+	} else {
+		b.riRun = 0
+	}
+	b.prev = c
+	b.has = true
+}
@@ -0,0 +1,294 @@
+// Command xxd is a thin CLI wrapper around the xxd package.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/EricLagergren/go-gnulib/ttyname"
+	"github.com/ericlagergren/go-coreutils/xxd"
+	flag "github.com/ogier/pflag"
+)
+
+// usage and version
+const (
+	Help = `Usage:
+       xxd [options] [infile [outfile]]
+    or
+       xxd -r [-s offset] [-c cols] [--ps] [infile [outfile]]
+Options:
+    -a, --autoskip     toggle autoskip: A single '*' replaces nul-lines. Default off.
+    -B, --bars         print pipes/bars before/after ASCII/EBCDIC output. Default off.
+    -b, --binary       binary digit dump (incompatible with -ps, -i, -r).Default hex.
+    -c, --cols         format <cols> octets per line. Default 16 (-i 12, --ps 30).
+    -E, --ebcdic       show characters in EBCDIC. Default ASCII.
+    -e, --little-endian  little-endian dump, grouped per -g octets (default 4).
+                       (incompatible with -b, -i, -ps, -r)
+    -R, --color        colorize hex and ASCII columns: 'always', 'auto'
+                       (default; colorize when stdout is a TTY), or 'never'.
+                       Honors NO_COLOR. Suppressed automatically with -r.
+    -g, --groups       number of octets per group in normal output. Default 2.
+    -h, --help         print this summary.
+    -i, --include      output in C include file style.
+    -l, --length       stop after <len> octets.
+    -o, --offset       add <offset> to the displayed file position.
+    -p, --ps           output in postscript plain hexdump style.
+        --radix        address column radix: 'hex' (default), 'decimal', or 'octal'.
+    -r, --reverse      reverse operation: convert (or patch) hexdump into ASCII output.
+                       * reversing non-hexdump formats require -r<flag> (i.e. -rb, -ri, -rp).
+    -s, --seek         start at <seek> bytes/bits in file. Byte/bit postfixes can be used.
+    		       * byte/bit postfix units are multiples of 1024.
+    		       * bits (kb, mb, etc.) will be rounded down to nearest byte.
+    -u, --uppercase    use upper case hex letters.
+    -v, --version      show version.`
+	Version = `xxd v2.0 2014-17-01 by Felix Geisendörfer and Eric Lagergren`
+)
+
+// cli flags
+var (
+	autoskip   = flag.BoolP("autoskip", "a", false, "toggle autoskip (* replaces nul lines")
+	bars       = flag.BoolP("bars", "B", false, "print |ascii| instead of ascii")
+	binary     = flag.BoolP("binary", "b", false, "binary dump, incompatible with -ps, -i, -r")
+	columns    = flag.IntP("cols", "c", -1, "format <cols> octets per line")
+	ebcdic     = flag.BoolP("ebcdic", "E", false, "use EBCDIC instead of ASCII")
+	littleEnd  = flag.BoolP("little-endian", "e", false, "little-endian hex dump")
+	color      = flag.StringP("color", "R", "auto", "colorize output: 'always', 'auto', or 'never'")
+	group      = flag.IntP("group", "g", -1, "num of octets per group")
+	cfmt       = flag.BoolP("include", "i", false, "output in C include format")
+	length     = flag.Int64P("len", "l", -1, "stop after len octets")
+	offset     = flag.Int64P("offset", "o", 0, "add offset to the displayed file position")
+	postscript = flag.BoolP("ps", "p", false, "output in postscript plain hd style")
+	radix      = flag.String("radix", "hex", "address column radix: 'hex', 'decimal', or 'octal'")
+	reverse    = flag.BoolP("reverse", "r", false, "convert hex to binary")
+	seek       = flag.StringP("seek", "s", "", "start at seek bytes abs")
+	upper      = flag.BoolP("uppercase", "u", false, "use uppercase hex letters")
+	version    = flag.BoolP("version", "v", false, "print version")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", Help)
+		os.Exit(0)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "no input file given\n%s\n", Help)
+		os.Exit(1)
+	}
+
+	if *version {
+		fmt.Fprintf(os.Stderr, "%s\n", Version)
+		os.Exit(0)
+	}
+
+	if flag.NArg() > 2 {
+		log.Fatalf("too many arguments after %s\n", flag.Arg(1))
+	}
+
+	if *littleEnd && (*binary || *cfmt || *postscript || *reverse) {
+		log.Fatalln("-e/--little-endian is incompatible with -b, -i, -ps, and -r")
+	}
+
+	var (
+		err  error
+		file string
+	)
+
+	if flag.NArg() >= 1 {
+		file = flag.Arg(0)
+	} else {
+		file = "-"
+	}
+
+	var inFile *os.File
+	if file == "-" {
+		inFile = os.Stdin
+		file = "stdin"
+	} else {
+		inFile, err = os.Open(file)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+	defer inFile.Close()
+
+	var seekOff int64
+	if *seek != "" {
+		seekOff = parseSeek(*seek)
+		if _, err := inFile.Seek(seekOff, os.SEEK_SET); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	var outFile *os.File
+	if flag.NArg() == 2 {
+		outFile, err = os.OpenFile(flag.Arg(1), os.O_RDWR|os.O_CREATE, 0660)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	} else {
+		outFile = os.Stdout
+	}
+	defer outFile.Close()
+
+	var format xxd.Format
+	switch {
+	case *binary:
+		format = xxd.Binary
+	case *cfmt:
+		format = xxd.CInclude
+	case *postscript:
+		format = xxd.Postscript
+	default:
+		format = xxd.Hex
+	}
+
+	cfg := xxd.Config{
+		Cols:         *columns,
+		Group:        *group,
+		Uppercase:    *upper,
+		Autoskip:     *autoskip,
+		Bars:         *bars,
+		EBCDIC:       *ebcdic,
+		Format:       format,
+		LittleEndian: *littleEnd,
+		Color:        !*reverse && resolveColor(*color, outFile),
+		Name:         file,
+		Seek:         seekOff,
+		Length:       *length,
+		Offset:       *offset,
+		Radix:        resolveRadix(*radix),
+	}
+
+	out := bufio.NewWriter(outFile)
+	defer out.Flush()
+
+	if *reverse {
+		if err := xxd.NewDecoder(out, cfg).Decode(inFile); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if err := xxd.NewEncoder(out, cfg).Encode(inFile); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// resolveColor turns a -R/--color value into a yes/no decision, honoring
+// NO_COLOR and, for "auto", whether out is a terminal.
+func resolveColor(mode string, out *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default: // "auto"
+		return ttyname.IsAtty(out.Fd())
+	}
+}
+
+// resolveRadix turns a --radix value into an xxd.Radix, defaulting to
+// xxd.HexRadix for an unrecognized value.
+func resolveRadix(mode string) xxd.Radix {
+	switch mode {
+	case "decimal", "dec", "d":
+		return xxd.DecimalRadix
+	case "octal", "oct", "o":
+		return xxd.OctalRadix
+	default:
+		return xxd.HexRadix
+	}
+}
+
+// parseSpecifier returns the multiplier implied by a 1- or 2-byte unit
+// suffix on a -s/--seek value (e.g. "k", "kb", "M", "Gb").
+func parseSpecifier(b string) float64 {
+	var b0, b1 byte
+	lb := len(b)
+
+	if lb < 2 {
+		if lb == 0 {
+			return 0
+		}
+		b0 = b[0]
+		b1 = '0'
+	} else {
+		b0 = b[0]
+		b1 = b[1]
+	}
+
+	if b1 != '0' {
+		if b1 == 'b' { // bits, so convert bytes to bits for os.Seek()
+			if b0 == 'k' || b0 == 'K' {
+				return 0.0078125
+			}
+			if b0 == 'm' || b0 == 'M' {
+				return 7.62939453125e-06
+			}
+			if b0 == 'g' || b0 == 'G' {
+				return 7.45058059692383e-09
+			}
+		}
+
+		if b1 == 'B' { // kilo/mega/giga- bytes are assumed
+			if b0 == 'k' || b0 == 'K' {
+				return 1024
+			}
+			if b0 == 'm' || b0 == 'M' {
+				return 1048576
+			}
+			if b0 == 'g' || b0 == 'G' {
+				return 1073741824
+			}
+		}
+	} else { // kilo/mega/giga- bytes are assumed for single b, k, m, g
+		if b0 == 'k' || b0 == 'K' {
+			return 1024
+		}
+		if b0 == 'm' || b0 == 'M' {
+			return 1048576
+		}
+		if b0 == 'g' || b0 == 'G' {
+			return 1073741824
+		}
+	}
+
+	return 1 // assumes bytes as fallback
+}
+
+// parseSeek parses a -s/--seek value into an absolute byte offset.
+func parseSeek(s string) int64 {
+	var (
+		sl    = len(s)
+		split int
+	)
+
+	if sl >= 2 {
+		if sl == 2 {
+			split = 1
+		} else {
+			split = 2
+		}
+	} else if sl != 0 {
+		split = 0
+	} else {
+		log.Fatalln("seek string somehow has len of 0")
+	}
+
+	mod := parseSpecifier(s[sl-split:])
+
+	ret, err := strconv.ParseFloat(s[:sl-split], 64)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return int64(ret * mod)
+}
@@ -0,0 +1,40 @@
+package xxd
+
+import "io"
+
+// ANSI SGR codes used to colorize dump output by byte category.
+const (
+	sgrReset  = "\x1b[0m"
+	sgrDim    = "\x1b[2m"  // null byte
+	sgrGreen  = "\x1b[32m" // printable ASCII
+	sgrYellow = "\x1b[33m" // whitespace
+	sgrRed    = "\x1b[31m" // other control bytes
+	sgrBlue   = "\x1b[34m" // high bytes (0x80-0xff)
+)
+
+// sgrFor returns the SGR escape sequence used to colorize b, or "" if b
+// isn't assigned a category (none currently fall through, but the empty
+// string keeps the type honest for callers).
+func sgrFor(b byte) string {
+	switch {
+	case b == 0:
+		return sgrDim
+	case b == '\t' || b == '\n' || b == '\v' || b == '\f' || b == '\r' || b == ' ':
+		return sgrYellow
+	case b > 0x1f && b < 0x7f:
+		return sgrGreen
+	case b >= 0x80:
+		return sgrBlue
+	default:
+		return sgrRed
+	}
+}
+
+// writeColored writes data (assumed to represent the single byte src) to w,
+// wrapped in the SGR sequence for src's category, then resets. Escape
+// sequences are zero-width so column alignment is unaffected.
+func writeColored(w io.Writer, src byte, data []byte) {
+	io.WriteString(w, sgrFor(src))
+	w.Write(data)
+	io.WriteString(w, sgrReset)
+}
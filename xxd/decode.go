@@ -0,0 +1,200 @@
+package xxd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Decoder reverses a hex dump, writing the original binary data to an
+// underlying io.Writer according to a Config.
+type Decoder struct {
+	w   io.Writer
+	cfg Config
+}
+
+// NewDecoder returns a Decoder that writes decoded bytes formatted per cfg
+// to w.
+func NewDecoder(w io.Writer, cfg Config) *Decoder {
+	return &Decoder{w: w, cfg: cfg}
+}
+
+// Decode reads a hex dump from r to completion, writing the decoded bytes
+// to the Decoder's underlying writer.
+func (d *Decoder) Decode(r io.Reader) error {
+	switch d.cfg.Format {
+	case Binary:
+		return d.decodeBinary(r)
+	case Postscript:
+		return d.decodeHexStream(r, 0)
+	case CInclude:
+		return d.decodeHexStream(r, 2)
+	default:
+		return d.decodeHex(r)
+	}
+}
+
+// decodeHex reverses the default Hex format, one line at a time. Each line
+// carries a leading "ADDR: " header, grouped hex octets, and a trailing
+// ASCII/EBCDIC gutter; hexDecode's double-space sentinel marks the
+// boundary between the two. The header is located by its trailing colon,
+// not parsed, so it accepts addresses in any Radix an Encoder produced.
+func (d *Decoder) decodeHex(r io.Reader) error {
+	cols := d.cfg.Cols
+	if d.cfg.Length > 0 && (cols == 0 || d.cfg.Length < int64(cols)) {
+		cols = int(d.cfg.Length)
+	}
+
+	char := make([]byte, 1)
+	c := int64(0)
+	rd := bufio.NewReader(r)
+	for {
+		line, err := rd.ReadBytes('\n')
+		n := len(line)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+
+		if i := bytes.IndexByte(line, ':'); i >= 0 && i < n-1 {
+			line = line[i+1:]
+			n = len(line)
+		}
+
+		for i := 0; n >= 2; {
+			if rv := hexDecode(char, line[i:i+2]); rv == 0 {
+				d.w.Write(char)
+				i += 2
+				n -= 2
+				c++
+			} else if rv == -1 {
+				i++
+				n--
+			} else { // rv == -2: double space, end of hex field
+				break
+			}
+		}
+
+		if cols > 0 && c == int64(cols) {
+			return nil
+		}
+	}
+}
+
+// decodeBinary reverses a Binary (-b) dump, line by line. Each line carries
+// a leading "ADDR: " header (any Radix), 8-bit groups separated by spaces,
+// and a trailing ASCII/EBCDIC gutter. Spaces before each group are
+// skipped, and the line ends at the first 8 bytes that do not form a clean
+// 8-bit group, which is where the gutter begins.
+func (d *Decoder) decodeBinary(r io.Reader) error {
+	cols := d.cfg.Cols
+	if d.cfg.Length > 0 && (cols == 0 || d.cfg.Length < int64(cols)) {
+		cols = int(d.cfg.Length)
+	}
+
+	char := make([]byte, 1)
+	c := int64(0)
+	rd := bufio.NewReader(r)
+	for {
+		line, err := rd.ReadBytes('\n')
+		n := len(line)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+
+		if i := bytes.IndexByte(line, ':'); i >= 0 && i < n-1 {
+			line = line[i+1:]
+			n = len(line)
+		}
+
+		for i := 0; i < n; {
+			for i < n && isSpace(&line[i]) {
+				i++
+			}
+			if i+8 > n {
+				break
+			}
+			if rv := binaryDecode(char, line[i:i+8]); rv == -1 {
+				d.w.Write(char)
+				i += 8
+				c++
+			} else {
+				// not a clean 8-bit group: we've hit the ASCII gutter.
+				break
+			}
+		}
+
+		if cols > 0 && c == int64(cols) {
+			return nil
+		}
+	}
+}
+
+// decodeHexStream reverses the Postscript (width 0) and CInclude (width 2)
+// formats. Both are just a stream of hex pairs embedded in otherwise
+// ignorable text (whitespace for Postscript; the "unsigned char NAME[] =
+// {...}" wrapper, "0x" prefixes, commas, and comments for CInclude), so
+// rather than rely on line structure we scan byte-by-byte for the next
+// decodable pair. skip is the number of bytes to discard before each pair
+// (2 for the "0x" prefix CInclude always emits, 0 for Postscript's bare
+// pairs).
+func (d *Decoder) decodeHexStream(r io.Reader, skip int) error {
+	rd := bufio.NewReader(r)
+	char := make([]byte, 1)
+	var pending []byte
+	for {
+		b, err := rd.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if skip == 2 {
+			// CInclude: only hex pairs immediately following "0x" count;
+			// everything else (the declaration, commas, comments, the
+			// trailing "unsigned int NAME_len = N;") is noise.
+			if b != '0' {
+				pending = nil
+				continue
+			}
+			nb, err := rd.Peek(1)
+			if err != nil || len(nb) == 0 || (nb[0] != 'x' && nb[0] != 'X') {
+				continue
+			}
+			rd.ReadByte() // consume 'x'/'X'
+
+			hi, err := rd.ReadByte()
+			if err != nil {
+				return nil
+			}
+			lo, err := rd.ReadByte()
+			if err != nil {
+				return nil
+			}
+			if hexDecode(char, []byte{hi, lo}) == 0 {
+				d.w.Write(char)
+			}
+			continue
+		}
+
+		// Postscript: skip whitespace, pair up consecutive hex digits.
+		if _, ok := fromHexChar(b); !ok {
+			pending = nil
+			continue
+		}
+		pending = append(pending, b)
+		if len(pending) == 2 {
+			if hexDecode(char, pending) == 0 {
+				d.w.Write(char)
+			}
+			pending = nil
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package xxd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+)
+
+var (
+	errLittleEndianFormat = errors.New("xxd: little-endian mode is only valid with hex output")
+	errLittleEndianGroup  = errors.New("xxd: little-endian group size must be 1, 2, 4, or 8")
+	errLittleEndianCols   = errors.New("xxd: --cols must be a multiple of the little-endian group size")
+)
+
+// Encoder writes hex dumps to an underlying io.Writer according to a
+// Config.
+type Encoder struct {
+	w   io.Writer
+	cfg Config
+}
+
+// NewEncoder returns an Encoder that writes dumps formatted per cfg to w.
+func NewEncoder(w io.Writer, cfg Config) *Encoder {
+	return &Encoder{w: w, cfg: cfg}
+}
+
+// writeHexDigits writes the two hex digits in char (encoding src), wrapped
+// in src's category color if the Encoder's Config requests it.
+func (e *Encoder) writeHexDigits(src byte, char []byte) {
+	if e.cfg.Color {
+		writeColored(e.w, src, char)
+		return
+	}
+	e.w.Write(char)
+}
+
+// Encode reads r to completion, writing a hex dump of its contents to the
+// Encoder's underlying writer.
+func (e *Encoder) Encode(r io.Reader) error {
+	cfg := e.cfg
+	w := e.w
+
+	if cfg.LittleEndian {
+		if cfg.Format != Hex {
+			return errLittleEndianFormat
+		}
+		leGroup := cfg.Group
+		if leGroup <= 0 {
+			leGroup = 4
+		}
+		switch leGroup {
+		case 1, 2, 4, 8:
+		default:
+			return errLittleEndianGroup
+		}
+		if cfg.Cols > 0 && cfg.Cols%leGroup != 0 {
+			return errLittleEndianCols
+		}
+	}
+
+	var (
+		lineOffset = cfg.Seek
+		addrBuf    []byte
+		width      = addrWidth(cfg.Seek+cfg.Offset+cfg.Length, cfg.Radix)
+		groupSize  int
+		cols       int
+		octs       int
+		caps       = ldigits
+		doCHeader  = true
+		doCEnd     bool
+		name       = identifier(cfg.Name)
+		// enough room for "unsigned char NAME[] = {"
+		varDeclChar = make([]byte, 14+len(name)+6)
+		// enough room for "unsigned int NAME_len = "
+		varDeclInt = make([]byte, 16+len(name)+7)
+		nulLine    int64
+	)
+
+	if cfg.Format == CInclude {
+		_ = copy(varDeclChar[0:14], unsignedChar[:])
+		_ = copy(varDeclInt[0:16], unsignedInt[:])
+		copy(varDeclChar[14:], name)
+		copy(varDeclInt[16:], name)
+		_ = copy(varDeclChar[14+len(name):], brackets[:])
+		_ = copy(varDeclInt[16+len(name):], lenEquals[:])
+	}
+
+	if cfg.Uppercase {
+		caps = udigits
+	}
+
+	if cfg.Cols <= 0 {
+		cols = colsDefault(cfg.Format)
+	} else {
+		cols = cfg.Cols
+	}
+
+	groupSize = groupDefault(cfg.Format)
+	octs = octsPerGroup(cfg.Format)
+	if cfg.Format == Postscript {
+		octs = 0
+	}
+
+	if cfg.Group > 0 {
+		groupSize = cfg.Group
+	} else if cfg.LittleEndian {
+		groupSize = 4
+	}
+
+	if cfg.Length > 0 && cfg.Length < int64(cols) {
+		cols = int(cfg.Length)
+	}
+
+	if octs < 1 {
+		octs = cols
+	}
+
+	var (
+		line = make([]byte, cols)
+		char = make([]byte, octs)
+	)
+
+	if cfg.Length > 0 {
+		r = io.LimitReader(r, cfg.Length)
+	}
+
+	c := int64(0)
+	rd := bufio.NewReader(r)
+	for {
+		n, err := io.ReadFull(rd, line)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		if cfg.Format == Postscript && n != 0 {
+			for i := 0; i < n; i++ {
+				hexEncode(char, line[i:i+1], caps)
+				w.Write(char)
+				c++
+			}
+			continue
+		}
+
+		if n == 0 {
+			if cfg.Format == Postscript {
+				w.Write(newLine)
+			}
+
+			if cfg.Format == CInclude {
+				doCEnd = true
+			} else {
+				return nil
+			}
+		}
+
+		if cfg.Autoskip && empty(line) {
+			if nulLine == 1 {
+				w.Write(asterisk)
+				w.Write(newLine)
+			}
+
+			nulLine++
+
+			if nulLine > 1 {
+				lineOffset += int64(n)
+				continue
+			}
+		}
+
+		if cfg.Format == Hex || cfg.Format == Binary {
+			addr := lineOffset + cfg.Offset
+			if w := addrWidth(addr, cfg.Radix); w > width {
+				width = w
+			}
+			addrBuf = formatAddr(addrBuf[:0], addr, cfg.Radix, width)
+			w.Write(addrBuf)
+			w.Write(colonSpace)
+			lineOffset += int64(n)
+		} else if doCHeader {
+			w.Write(varDeclChar)
+			w.Write(newLine)
+			doCHeader = false
+		}
+
+		switch cfg.Format {
+		case Binary:
+			for i, k := 0, octs; i < n; i, k = i+1, k+octs {
+				binaryEncode(char, line[i:i+1])
+				w.Write(char)
+				c++
+
+				if k == octs*groupSize || i == cols-1 {
+					k = 0
+					w.Write(space)
+				}
+			}
+		case CInclude:
+			if !doCEnd {
+				w.Write(doubleSpace)
+			}
+
+			for i := 0; i < n; i++ {
+				cfmtEncode(char, line[i:i+1], caps)
+				w.Write(char)
+				c++
+
+				if i != n-1 {
+					w.Write(commaSpace)
+				} else if doCEnd {
+					w.Write(comma)
+				}
+			}
+		case Hex:
+			if cfg.LittleEndian {
+				for i := 0; i < n; i += groupSize {
+					end := i + groupSize
+					if end > n {
+						end = n
+					}
+					for j := end - 1; j >= i; j-- {
+						hexEncode(char, line[j:j+1], caps)
+						e.writeHexDigits(line[j], char)
+						c++
+					}
+					w.Write(space)
+				}
+			} else {
+				for i, k := 0, octs; i < n; i, k = i+1, k+octs {
+					hexEncode(char, line[i:i+1], caps)
+					e.writeHexDigits(line[i], char)
+					c++
+
+					if k == octs*groupSize || i == cols-1 {
+						k = 0
+						w.Write(space)
+					}
+				}
+			}
+		}
+
+		if doCEnd {
+			w.Write(varDeclInt)
+			w.Write([]byte(strconv.FormatInt(c, 10)))
+			w.Write(semiColonNl)
+			return nil
+		}
+
+		if n < cols && (cfg.Format == Hex || cfg.Format == Binary) {
+			lineLen := cols*octs + ((cols * octs) / (octs * groupSize))
+			pos := n*octs + ((n * octs) / (octs * groupSize))
+			for i := pos; i < lineLen; i++ {
+				w.Write(space)
+			}
+		}
+
+		if cfg.Format != CInclude {
+			w.Write(space)
+		}
+
+		if cfg.Format == Hex || cfg.Format == Binary {
+			b := line[:n]
+			if cfg.Bars {
+				w.Write(bar)
+			}
+			if cfg.EBCDIC {
+				for _, ch := range b {
+					if ch >= ebcdicOffset {
+						eb := ebcdicTable[ch-ebcdicOffset : ch-ebcdicOffset+1]
+						if eb[0] > 0x1f && eb[0] < 0x7f {
+							w.Write(eb)
+						} else {
+							w.Write(dot)
+						}
+					} else {
+						w.Write(dot)
+					}
+				}
+			} else {
+				for i, ch := range b {
+					if ch > 0x1f && ch < 0x7f {
+						if cfg.Color {
+							writeColored(w, ch, line[i:i+1])
+						} else {
+							w.Write(line[i : i+1])
+						}
+					} else {
+						if cfg.Color {
+							writeColored(w, ch, dot)
+						} else {
+							w.Write(dot)
+						}
+					}
+				}
+			}
+			if cfg.Bars {
+				w.Write(bar)
+			}
+		}
+		w.Write(newLine)
+	}
+	return nil
+}
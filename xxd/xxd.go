@@ -1,96 +1,153 @@
-package main
+// Package xxd implements hex-dump encoding and decoding compatible with the
+// xxd(1) utility. It is built around io.Reader/io.Writer so callers can
+// embed hex dumping without shelling out to the xxd binary.
+package xxd
 
-import (
-	"bufio"
-	"fmt"
-	"io"
-	"log"
-	"os"
-	"strconv"
+import "strconv"
 
-	flag "github.com/ogier/pflag"
-)
+// Format selects the dump style produced by an Encoder or expected by a
+// Decoder.
+type Format int
 
-// usage and version
 const (
-	Help = `Usage:
-       xxd [options] [infile [outfile]]
-    or
-       xxd -r [-s offset] [-c cols] [--ps] [infile [outfile]]
-Options:
-    -a, --autoskip     toggle autoskip: A single '*' replaces nul-lines. Default off.
-    -B, --bars         print pipes/bars before/after ASCII/EBCDIC output. Default off.
-    -b, --binary       binary digit dump (incompatible with -ps, -i, -r).Default hex.
-    -c, --cols         format <cols> octets per line. Default 16 (-i 12, --ps 30).
-    -E, --ebcdic       show characters in EBCDIC. Default ASCII.
-    -g, --groups       number of octets per group in normal output. Default 2.
-    -h, --help         print this summary.
-    -i, --include      output in C include file style.
-    -l, --length       stop after <len> octets.
-    -p, --ps           output in postscript plain hexdump style.
-    -r, --reverse      reverse operation: convert (or patch) hexdump into ASCII output.
-                       * reversing non-hexdump formats require -r<flag> (i.e. -rb, -ri, -rp).
-    -s, --seek         start at <seek> bytes/bits in file. Byte/bit postfixes can be used.
-    		       * byte/bit postfix units are multiples of 1024.
-    		       * bits (kb, mb, etc.) will be rounded down to nearest byte.
-    -u, --uppercase    use upper case hex letters.
-    -v, --version      show version.`
-	Version = `xxd v2.0 2014-17-01 by Felix Geisend√∂rfer and Eric Lagergren`
+	// Hex is the default xxd output: a hex offset, grouped hex octets, and
+	// an ASCII/EBCDIC gutter.
+	Hex Format = iota
+	// Binary prints each octet as eight '0'/'1' characters (xxd -b).
+	Binary
+	// Postscript prints a continuous run of hex pairs with no offsets or
+	// gutter (xxd -p).
+	Postscript
+	// CInclude prints a "static const unsigned char NAME[] = {...}" style
+	// C array (xxd -i).
+	CInclude
 )
 
-// cli flags
-var (
-	autoskip   = flag.BoolP("autoskip", "a", false, "toggle autoskip (* replaces nul lines")
-	bars       = flag.BoolP("bars", "B", false, "print |ascii| instead of ascii")
-	binary     = flag.BoolP("binary", "b", false, "binary dump, incompatible with -ps, -i, -r")
-	columns    = flag.IntP("cols", "c", -1, "format <cols> octets per line")
-	ebcdic     = flag.BoolP("ebcdic", "E", false, "use EBCDIC instead of ASCII")
-	group      = flag.IntP("group", "g", -1, "num of octets per group")
-	cfmt       = flag.BoolP("include", "i", false, "output in C include format")
-	length     = flag.Int64P("len", "l", -1, "stop after len octets")
-	postscript = flag.BoolP("ps", "p", false, "output in postscript plain hd style")
-	reverse    = flag.BoolP("reverse", "r", false, "convert hex to binary")
-	offset     = flag.Int("off", 0, "revert with offset")
-	seek       = flag.StringP("seek", "s", "", "start at seek bytes abs")
-	upper      = flag.BoolP("uppercase", "u", false, "use uppercase hex letters")
-	version    = flag.BoolP("version", "v", false, "print version")
-)
+// Radix selects the numeral system used to print the address column of Hex
+// and Binary output. It has no effect on Postscript or CInclude output,
+// neither of which has an address column.
+type Radix int
 
-// constants used in xxd()
 const (
-	ebcdicOffset = 0x40
+	// HexRadix prints addresses in hexadecimal. It is the default.
+	HexRadix Radix = iota
+	// DecimalRadix prints addresses in decimal.
+	DecimalRadix
+	// OctalRadix prints addresses in octal.
+	OctalRadix
 )
 
-// dumpType enum
-const (
-	dumpHex = iota
-	dumpBinary
-	dumpCformat
-	dumpPostscript
-)
+// base returns the numeral base r represents.
+func (r Radix) base() int {
+	switch r {
+	case DecimalRadix:
+		return 10
+	case OctalRadix:
+		return 8
+	default:
+		return 16
+	}
+}
 
-// variables used in xxd*()
-var (
-	dumpType int
+// addrWidth returns the number of digits needed to print addr in radix r,
+// no smaller than 7 (matching the fixed width the original zeroHeader-based
+// formatting always padded to).
+func addrWidth(addr int64, r Radix) int {
+	w := len(strconv.AppendInt(nil, addr, r.base()))
+	if w < 7 {
+		w = 7
+	}
+	return w
+}
 
-	space        = []byte(" ")
-	doubleSpace  = []byte("  ")
-	dot          = []byte(".")
-	newLine      = []byte("\n")
-	zeroHeader   = []byte("0000000: ")
-	unsignedChar = []byte("unsigned char ")
-	unsignedInt  = []byte("};\nunsigned int ")
-	lenEquals    = []byte("_len = ")
-	brackets     = []byte("[] = {")
-	asterisk     = []byte("*")
-	hexPrefix    = []byte("0x")
-	commaSpace   = []byte(", ")
-	comma        = []byte(",")
-	semiColonNl  = []byte(";\n")
-	bar          = []byte("|")
+// formatAddr appends addr, formatted in radix r and zero-padded to width,
+// to dst, returning the extended slice. It is used by Encoder to print the
+// address column; Decoder doesn't need the inverse, since it locates the
+// address column by its trailing ": " rather than by parsing its digits.
+func formatAddr(dst []byte, addr int64, r Radix, width int) []byte {
+	buf := strconv.AppendInt(nil, addr, r.base())
+	for i := len(buf); i < width; i++ {
+		dst = append(dst, '0')
+	}
+	return append(dst, buf...)
+}
+
+// Config controls how an Encoder formats its output, or how a Decoder
+// interprets its input.
+type Config struct {
+	// Cols is the number of octets dumped per line. Zero selects the
+	// format's default (16 for Hex, 6 for Binary, 30 for Postscript, 12 for
+	// CInclude). Postscript output is not split into lines: it is one
+	// continuous run of hex pairs ending in a single newline.
+	Cols int
+
+	// Group is the number of octets grouped together between spaces in Hex
+	// and Binary output. Zero selects the format's default (2 for Hex, 1
+	// for Binary).
+	Group int
+
+	// Uppercase selects upper-case hex digits.
+	Uppercase bool
+
+	// Autoskip replaces runs of all-nul lines with a single '*' line.
+	Autoskip bool
+
+	// Bars wraps the ASCII/EBCDIC gutter in '|' characters.
+	Bars bool
+
+	// EBCDIC renders the gutter as EBCDIC instead of ASCII.
+	EBCDIC bool
+
+	// Format selects the dump style.
+	Format Format
+
+	// LittleEndian prints each Group of octets as a single little-endian
+	// hex word instead of individual big-endian bytes (xxd -e). It is only
+	// meaningful for Hex output; Group must be one of 1, 2, 4, or 8, and
+	// zero or less selects 4.
+	LittleEndian bool
+
+	// Color wraps the hex column of Hex output, and the ASCII gutter of
+	// Hex and Binary output, in ANSI SGR escapes, categorizing each byte
+	// as null, printable, whitespace, control, or high (0x80-0xff). The
+	// EBCDIC gutter is written without color. Resolving "auto" (TTY detection) and
+	// NO_COLOR are the caller's responsibility; the Encoder always honors
+	// whatever Color is set to.
+	Color bool
+
+	// Name is used to derive the C identifier in CInclude output (commonly
+	// the input file name). Non-identifier bytes are replaced with '_'.
+	Name string
+
+	// Seek, if non-zero, is reported as the starting offset of the first
+	// line of Hex/Binary output. It does not itself seek the reader; the
+	// caller positions r and sets Seek to match.
+	Seek int64
+
+	// Length, if positive, stops the dump after Length octets.
+	Length int64
+
+	// Offset biases every displayed address by a fixed amount without
+	// affecting where the dump starts reading (see Seek). Useful when
+	// dumping a slice of a larger image, e.g. firmware meant to be loaded
+	// at a particular address.
+	Offset int64
+
+	// Radix selects the numeral system of the address column in Hex and
+	// Binary output. The zero value is HexRadix.
+	Radix Radix
+}
+
+// hex digit tables shared by encoders and decoders.
+const (
+	ldigits = "0123456789abcdef"
+	udigits = "0123456789ABCDEF"
 )
 
-// ascii -> ebcdic lookup table
+// ebcdicOffset is the first byte with a table entry in ebcdicTable.
+const ebcdicOffset = 0x40
+
+// ebcdicTable maps ASCII bytes >= ebcdicOffset to their EBCDIC equivalent.
 var ebcdicTable = []byte{
 	0040, 0240, 0241, 0242, 0243, 0244, 0245, 0246,
 	0247, 0250, 0325, 0056, 0074, 0050, 0053, 0174,
@@ -118,59 +175,27 @@ var ebcdicTable = []byte{
 	0070, 0071, 0372, 0373, 0374, 0375, 0376, 0377,
 }
 
-// convert a byte into its binary representation
-func binaryEncode(dst, src []byte) {
-	d := uint(0)
-	for i := 7; i >= 0; i-- {
-		if src[0]&(1<<d) == 0 {
-			dst[i] = '0'
-		} else {
-			dst[i] = '1'
-		}
-		d++
-	}
-}
-
-// returns -1 on success
-// returns k > -1 if space found where k is index of space byte
-func binaryDecode(dst, src []byte) int {
-	var d byte
-
-	for i, v := range src {
-		d <<= 1
-		if isSpace(&v) { // found a space, so between groups
-			if i == 0 {
-				return 1
-			}
-			return i
-		}
-		if v == '1' {
-			d ^= 1
-		} else if v != '0' {
-			return i // will catch issues like "000000: "
-		}
-	}
-
-	dst[0] = d
-	return -1
-}
-
-// hex lookup table for hex encoding
-const (
-	ldigits = "0123456789abcdef"
-	udigits = "0123456789ABCDEF"
+var (
+	space        = []byte(" ")
+	doubleSpace  = []byte("  ")
+	dot          = []byte(".")
+	newLine      = []byte("\n")
+	colonSpace   = []byte(": ")
+	unsignedChar = []byte("unsigned char ")
+	unsignedInt  = []byte("};\nunsigned int ")
+	lenEquals    = []byte("_len = ")
+	brackets     = []byte("[] = {")
+	asterisk     = []byte("*")
+	hexPrefix    = []byte("0x")
+	commaSpace   = []byte(", ")
+	comma        = []byte(",")
+	semiColonNl  = []byte(";\n")
+	bar          = []byte("|")
 )
 
-func cfmtEncode(dst, src []byte, hextable string) {
-	dst[0] = '0'
-	dst[1] = 'x'
-	for i, v := range src {
-		dst[i+1*2] = hextable[v>>4]
-		dst[i+1*2+1] = hextable[v&0x0f]
-	}
-}
-
-// copied from encoding/hex package in order to add support for uppercase hex
+// hexEncode writes the hex encoding of src into dst using hextable for
+// digits. len(dst) must be 2*len(src). Adapted from encoding/hex to support
+// uppercase digits.
 func hexEncode(dst, src []byte, hextable string) {
 	for i, v := range src {
 		dst[i*2] = hextable[v>>4]
@@ -178,10 +203,11 @@ func hexEncode(dst, src []byte, hextable string) {
 	}
 }
 
-// copied from encoding/hex package
-// returns -1 on bad byte or space (\t \s \n)
-// returns -2 on two consecutive spaces
-// returns 0 on success
+// hexDecode decodes a single hex-encoded byte from src into dst[0].
+//
+// It returns -1 on a bad byte or a lone space (space, tab, or form
+// feed), -2 on two
+// consecutive spaces, and 0 on success. Adapted from encoding/hex.
 func hexDecode(dst, src []byte) int {
 	if isSpace(&src[0]) {
 		if isSpace(&src[1]) {
@@ -209,7 +235,8 @@ func hexDecode(dst, src []byte) int {
 	return 0
 }
 
-// copied from encoding/hex package
+// fromHexChar converts a hex character into its value. Adapted from
+// encoding/hex.
 func fromHexChar(c byte) (byte, bool) {
 	switch {
 	case '0' <= c && c <= '9':
@@ -223,586 +250,126 @@ func fromHexChar(c byte) (byte, bool) {
 	return 0, false
 }
 
-// check if entire line is full of empty []byte{0} bytes (nul in C)
-func empty(b []byte) bool {
-	for _, v := range b {
-		if v != 0 {
-			return false
+// binaryEncode writes the 8-bit binary representation of src[0] into dst.
+func binaryEncode(dst, src []byte) {
+	d := uint(0)
+	for i := 7; i >= 0; i-- {
+		if src[0]&(1<<d) == 0 {
+			dst[i] = '0'
+		} else {
+			dst[i] = '1'
 		}
+		d++
 	}
-	return true
 }
 
-// quick binary tree check
-// probably horribly written idk it's late at night
-func parseSpecifier(b string) float64 {
-	var b0, b1 byte
-	lb := len(b)
-
-	if lb < 2 {
-		if lb == 0 {
-			return 0
-		}
-		b0 = b[0]
-		b1 = '0'
-	} else {
-		b0 = b[0]
-		b1 = b[1]
-	}
-
-	if b1 != '0' {
-		if b1 == 'b' { // bits, so convert bytes to bits for os.Seek()
-			if b0 == 'k' || b0 == 'K' {
-				return 0.0078125
-			}
-
-			if b0 == 'm' || b0 == 'M' {
-				return 7.62939453125e-06
-			}
-
-			if b0 == 'g' || b0 == 'G' {
-				return 7.45058059692383e-09
-			}
-		}
-
-		if b1 == 'B' { // kilo/mega/giga- bytes are assumed
-			if b0 == 'k' || b0 == 'K' {
-				return 1024
-			}
-
-			if b0 == 'm' || b0 == 'M' {
-				return 1048576
-			}
+// binaryDecode decodes an 8-bit binary group from src into dst[0].
+//
+// It returns -1 on success. Otherwise it returns the index of the first
+// byte that is not '0' or '1' (a space or anything else), except that a
+// space at index 0 returns 1.
+func binaryDecode(dst, src []byte) int {
+	var d byte
 
-			if b0 == 'g' || b0 == 'G' {
-				return 1073741824
+	for i, v := range src {
+		d <<= 1
+		if isSpace(&v) {
+			if i == 0 {
+				return 1
 			}
+			return i
 		}
-	} else { // kilo/mega/giga- bytes are assumed for single b, k, m, g
-		if b0 == 'k' || b0 == 'K' {
-			return 1024
-		}
-
-		if b0 == 'm' || b0 == 'M' {
-			return 1048576
-		}
-
-		if b0 == 'g' || b0 == 'G' {
-			return 1073741824
+		if v == '1' {
+			d ^= 1
+		} else if v != '0' {
+			return i
 		}
 	}
 
-	return 1 // assumes bytes as fallback
+	dst[0] = d
+	return -1
 }
 
-// parses *seek input
-func parseSeek(s string) int64 {
-	var (
-		sl    = len(s)
-		split int
-	)
-
-	if sl >= 2 {
-		if sl == 2 {
-			split = 1
-		} else {
-			split = 2
-		}
-	} else if sl != 0 {
-		split = 0
-	} else {
-		log.Fatalln("seek string somehow has len of 0")
+// cfmtEncode writes "0xHH" for src[0] into dst using hextable for digits.
+func cfmtEncode(dst, src []byte, hextable string) {
+	dst[0] = '0'
+	dst[1] = 'x'
+	for i, v := range src {
+		dst[i+1*2] = hextable[v>>4]
+		dst[i+1*2+1] = hextable[v&0x0f]
 	}
+}
 
-	mod := parseSpecifier(s[sl-split:])
-
-	ret, err := strconv.ParseFloat(s[:sl-split], 64) //64 bit float
-	if err != nil {
-		log.Fatalln(err)
+// empty reports whether b is entirely nul bytes.
+func empty(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
 	}
-
-	return int64(ret * mod)
+	return true
 }
 
-// is byte a space? (\t, \n, \s)
+// isSpace reports whether b is a space, tab, or form feed.
 func isSpace(b *byte) bool {
 	return *b == 32 || *b == 9 || *b == 12
 }
 
-// are the two bytes hex prefixes? (0x or 0X)
+// isPrefix reports whether b is a "0x"/"0X" prefix.
 func isPrefix(b []byte) bool {
 	return b[0] == '0' && (b[1] == 'x' || b[1] == 'X')
 }
 
-func xxdReverse(r io.Reader, w io.Writer) error {
-	var (
-		cols int
-		octs int
-		char = make([]byte, 1)
-	)
-
-	if *columns != -1 {
-		cols = *columns
-	}
-
-	switch dumpType {
-	case dumpBinary:
-		octs = 8
-	case dumpCformat:
-		octs = 4
+// colsDefault returns the default column count for f.
+func colsDefault(f Format) int {
+	switch f {
+	case Postscript:
+		return 30
+	case CInclude:
+		return 12
+	case Binary:
+		return 6
 	default:
-		octs = 2
+		return 16
 	}
-
-	if *length != -1 {
-		if *length < int64(cols) {
-			cols = int(*length)
-		}
-	}
-
-	if octs < 1 {
-		octs = cols
-	}
-
-	c := int64(0) // number of characters
-	rd := bufio.NewReader(r)
-	for {
-		line, err := rd.ReadBytes('\n') // read up until a newline
-		n := len(line)
-		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
-			return err
-		}
-
-		if n == 0 {
-			return nil
-		}
-
-		if dumpType == dumpHex {
-			for i := 0; n >= octs; {
-				if rv := hexDecode(char, line[i:i+octs]); rv == 0 {
-					w.Write(char)
-					i += 2
-					n -= 2
-					c++
-				} else if rv == -1 {
-					i++
-					n--
-				} else { // if rv == -2
-					i += 2
-					n -= 2
-				}
-			}
-		} else if dumpType == dumpBinary {
-			for i := 0; n >= octs; {
-				if binaryDecode(char, line[i:i+octs]) != -1 {
-					i++
-					n--
-					continue
-				} else {
-					w.Write(char)
-					i += 8
-					n -= 8
-					c++
-				}
-			}
-		} else if dumpType == dumpPostscript {
-			for i := 0; n >= octs; i++ {
-				if hexDecode(char, line[i:i+octs]) == 0 {
-					w.Write(char)
-					c++
-				}
-				n--
-			}
-		} else if dumpType == dumpCformat {
-			for i := 0; n >= octs; {
-				if rv := hexDecode(char, line[i:i+octs]); rv == 0 {
-					w.Write(char)
-					i += 4
-					n -= 4
-					c++
-				} else if rv == -1 {
-					i++
-					n--
-				} else { // if rv == -2
-					i += 2
-					n -= 2
-				}
-			}
-		}
-
-		// For some reason "xxd FILE | xxd -r -c N" truncates the output,
-		// so we'll do it as well
-		// "xxd FILE | xxd -r -l N" doesn't truncate
-		if c == int64(cols) && cols > 0 {
-			return nil
-		}
-	}
-	return nil
 }
 
-func xxd(r io.Reader, w io.Writer, fname string) error {
-	var (
-		lineOffset int64
-		hexOffset  = make([]byte, 6)
-		groupSize  int
-		cols       int
-		octs       int
-		caps       = ldigits
-		doCHeader  = true
-		doCEnd     bool
-		// enough room for "unsigned char NAME_FORMAT[] = {"
-		varDeclChar = make([]byte, 14+len(fname)+6)
-		// enough room for "unsigned int NAME_FORMAT = "
-		varDeclInt = make([]byte, 16+len(fname)+7)
-		nulLine    int64
-		totalOcts  int64
-	)
-
-	// Generate the first and last line in the -i output:
-	// e.g. unsigned char foo_txt[] = { and unsigned int foo_txt_len =
-	if dumpType == dumpCformat {
-		// copy over "unnsigned char " and "unsigned int"
-		_ = copy(varDeclChar[0:14], unsignedChar[:])
-		_ = copy(varDeclInt[0:16], unsignedInt[:])
-
-		for i := 0; i < len(fname); i++ {
-			if fname[i] != '.' {
-				varDeclChar[14+i] = fname[i]
-				varDeclInt[16+i] = fname[i]
-			} else {
-				varDeclChar[14+i] = '_'
-				varDeclInt[16+i] = '_'
-			}
-		}
-		// copy over "[] = {" and "_len = "
-		_ = copy(varDeclChar[14+len(fname):], brackets[:])
-		_ = copy(varDeclInt[16+len(fname):], lenEquals[:])
-	}
-
-	// Switch between upper- and lower-case hex chars
-	if *upper {
-		caps = udigits
-	}
-
-	// xxd -bpi FILE outputs in binary format
-	// xxd -b -p -i FILE outputs in C format
-	// simply catch the last option since that's what I assume the author
-	// wanted...
-	if *columns == -1 {
-		switch dumpType {
-		case dumpPostscript:
-			cols = 30
-		case dumpCformat:
-			cols = 12
-		case dumpBinary:
-			cols = 6
-		default:
-			cols = 16
-		}
-	} else {
-		cols = *columns
-	}
-
-	// See above comment
-	switch dumpType {
-	case dumpBinary:
-		octs = 8
-		groupSize = 1
-	case dumpPostscript:
-		octs = 0
-	case dumpCformat:
-		octs = 4
+// groupDefault returns the default group size for f.
+func groupDefault(f Format) int {
+	switch f {
+	case Binary:
+		return 1
+	case Postscript, CInclude:
+		return 0
 	default:
-		octs = 2
-		groupSize = 2
+		return 2
 	}
-
-	if *group != -1 {
-		groupSize = *group
-	}
-
-	// If -l is smaller than the number of cols just truncate the cols
-	if *length != -1 {
-		if *length < int64(cols) {
-			cols = int(*length)
-		}
-	}
-
-	if octs < 1 {
-		octs = cols
-	}
-
-	// These are bumped down from the beginning of the function in order to
-	// allow their sizes to be allocated based on the user's speficiations
-	var (
-		line = make([]byte, cols)
-		char = make([]byte, octs)
-	)
-
-	c := int64(0) // number of characters
-	nl := int64(0)
-	r = bufio.NewReader(r)
-	for {
-		n, err := io.ReadFull(r, line)
-		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
-			return err
-		}
-
-		// Speed it up a bit ;)
-		if dumpType == dumpPostscript && n != 0 {
-			// Post script values
-			// Basically just raw hex output
-			for i := 0; i < n; i++ {
-				hexEncode(char, line[i:i+1], caps)
-				w.Write(char)
-				c++
-			}
-			continue
-		}
-
-		if n == 0 {
-			if dumpType == dumpPostscript {
-				w.Write(newLine)
-			}
-
-			if dumpType == dumpCformat {
-				doCEnd = true
-			} else {
-				return nil // Hidden return!
-			}
-		}
-
-		if *length != -1 {
-			if totalOcts == *length {
-				break
-			}
-			totalOcts += *length
-		}
-
-		if *autoskip && empty(line) {
-			if nulLine == 1 {
-				w.Write(asterisk)
-				w.Write(newLine)
-			}
-
-			nulLine++
-
-			if nulLine > 1 {
-				lineOffset += int64(n) // continue to increment our offset
-				continue
-			}
-		}
-
-		if dumpType <= dumpBinary { // either hex or binary
-			// Line offset
-			hexOffset = strconv.AppendInt(hexOffset[0:0], lineOffset, 16)
-			w.Write(zeroHeader[0:(7 - len(hexOffset))])
-			w.Write(hexOffset)
-			w.Write(zeroHeader[7:])
-			lineOffset += int64(n)
-		} else if doCHeader {
-			w.Write(varDeclChar)
-			w.Write(newLine)
-			doCHeader = false
-		}
-
-		if dumpType == dumpBinary {
-			// Binary values
-			for i, k := 0, octs; i < n; i, k = i+1, k+octs {
-				binaryEncode(char, line[i:i+1])
-				w.Write(char)
-				c++
-
-				if k == octs*groupSize || i == cols-1 {
-					k = 0
-					w.Write(space)
-				}
-			}
-		} else if dumpType == dumpCformat {
-			// C values
-			if !doCEnd {
-				w.Write(doubleSpace)
-			}
-
-			for i := 0; i < n; i++ {
-				cfmtEncode(char, line[i:i+1], caps)
-				w.Write(char)
-				c++
-
-				// don't add spaces to EOL
-				if i != n-1 {
-					w.Write(commaSpace)
-				} else if doCEnd {
-					w.Write(comma)
-				}
-			}
-		} else {
-			// Hex values -- default xxd FILE output
-			for i, k := 0, octs; i < n; i, k = i+1, k+octs {
-				hexEncode(char, line[i:i+1], caps)
-				w.Write(char)
-				c++
-
-				if k == octs*groupSize || i == cols-1 {
-					k = 0 // reset counter
-					w.Write(space)
-				}
-			}
-		}
-
-		if doCEnd {
-			w.Write(varDeclInt)
-			w.Write([]byte(strconv.FormatInt(c, 10)))
-			w.Write(semiColonNl)
-			return nil
-		}
-
-		// If we didn't read a full line, determine our position
-		// and fill the rest of the line with spaces.
-		if n < cols && dumpType <= dumpBinary {
-
-			lineLen := cols*octs + ((cols * octs) / (octs * groupSize))
-			pos := n*octs + ((n * octs) / (octs * groupSize))
-			for i := pos; i < lineLen; i++ {
-				w.Write(space)
-			}
-		}
-
-		if dumpType != dumpCformat {
-			w.Write(space)
-		}
-
-		if dumpType <= dumpBinary {
-			// Character values
-			b := line[:n]
-			// |hello, world!| instead of hello, world!
-			if *bars {
-				w.Write(bar)
-			}
-			// EBCDIC
-			if *ebcdic {
-				for _, c := range b {
-					if c >= ebcdicOffset {
-						e := ebcdicTable[c-ebcdicOffset : c-ebcdicOffset+1]
-						if e[0] > 0x1f && e[0] < 0x7f {
-							w.Write(e)
-						} else {
-							w.Write(dot)
-						}
-					} else {
-						w.Write(dot)
-					}
-				}
-				if *bars {
-					w.Write(bar)
-				}
-				// ASCII
-			} else {
-				for i, c := range b {
-					if c > 0x1f && c < 0x7f {
-						w.Write(line[i : i+1])
-					} else {
-						w.Write(dot)
-					}
-				}
-			}
-			if *bars {
-				w.Write(bar)
-			}
-		}
-		w.Write(newLine)
-		nl++
-	}
-	return nil
 }
 
-func main() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "%s\n", Help)
-		os.Exit(0)
-	}
-	flag.Parse()
-
-	if flag.NArg() == 0 {
-		fmt.Fprintf(os.Stderr, "no input file given\n%s\n", Help)
-		os.Exit(1)
-	}
-
-	if *version {
-		fmt.Fprintf(os.Stderr, "%s\n", Version)
-		os.Exit(0)
-	}
-
-	if flag.NArg() > 2 {
-		log.Fatalf("too many arguments after %s\n", flag.Arg(1))
-	}
-
-	var (
-		err  error
-		file string
-	)
-
-	if flag.NArg() >= 1 {
-		file = flag.Arg(0)
-	} else {
-		file = "-"
-	}
-
-	var inFile *os.File
-	if file == "-" {
-		inFile = os.Stdin
-		file = "stdin"
-	} else {
-		inFile, err = os.Open(file)
-		if err != nil {
-			log.Fatalln(err)
-		}
-	}
-	defer inFile.Close()
-
-	// Start *seek bytes into file
-	if *seek != "" {
-		sv := parseSeek(*seek)
-		_, err := inFile.Seek(sv, os.SEEK_SET)
-		if err != nil {
-			log.Fatalln(err)
-		}
-	}
-
-	var outFile *os.File
-	if flag.NArg() == 2 {
-		outFile, err = os.OpenFile(flag.Arg(1), os.O_RDWR|os.O_CREATE, 0660)
-		if err != nil {
-			log.Fatalln(err)
-		}
-	} else {
-		outFile = os.Stdout
-	}
-	defer outFile.Close()
-
-	switch true {
-	case *binary:
-		dumpType = dumpBinary
-	case *cfmt:
-		dumpType = dumpCformat
-	case *postscript:
-		dumpType = dumpPostscript
+// octsPerGroup returns how many bytes of output one dumped octet occupies
+// for f (8 binary digits, 2 hex digits, or 4 characters for "0xHH").
+func octsPerGroup(f Format) int {
+	switch f {
+	case Binary:
+		return 8
+	case CInclude:
+		return 4
 	default:
-		dumpType = dumpHex
+		return 2
 	}
+}
 
-	out := bufio.NewWriter(outFile)
-	defer out.Flush()
-
-	if *reverse {
-		if err := xxdReverse(inFile, out); err != nil {
-			log.Fatalln(err)
-		}
-		return
-	} else {
-		if err := xxd(inFile, out, file); err != nil {
-			log.Fatalln(err)
+// identifier replaces any byte in name that is not a valid C identifier
+// character with '_'.
+func identifier(name string) string {
+	b := []byte(name)
+	for i := range b {
+		c := b[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			b[i] = '_'
 		}
-		return
 	}
+	return string(b)
 }
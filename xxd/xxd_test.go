@@ -1,12 +1,11 @@
-package main
+package xxd
 
 import (
 	"bytes"
 	"crypto/rand"
 	"flag"
-	"fmt"
 	"io"
-	"io/ioutil"
+	"os"
 	"os/exec"
 	"strings"
 	"testing"
@@ -15,26 +14,30 @@ import (
 
 var xxdFile = flag.String("xxdFile", "", "File to test against.")
 
-func TestXXD(t *testing.T) {
+// TestEncode checks the Encoder's default Hex output against the system
+// xxd binary across a range of pathological read sizes.
+func TestEncode(t *testing.T) {
 	if *xxdFile == "" {
 		t.Skip("-xxdFile argument not given")
 	}
-	data, err := ioutil.ReadFile(*xxdFile)
+	data, err := os.ReadFile(*xxdFile)
 	if err != nil {
 		t.Fatal(err)
 	}
-	test := func(fn func(r io.Reader, w io.Writer, s string) error) func(n uint64) []string {
+	test := func(fn func(r io.Reader, w io.Writer) error) func(n uint64) []string {
 		return func(n uint64) []string {
 			size := n % uint64(len(data))
-			fmt.Printf("%d\n", size)
 			var out bytes.Buffer
-			if err := fn(&pathologicalReader{data[0:size]}, &out, ""); err != nil {
+			if err := fn(&pathologicalReader{data[0:size]}, &out); err != nil {
 				return []string{err.Error()}
 			}
 			return strings.Split(out.String(), "\n")
 		}
 	}
-	if err := quick.CheckEqual(test(xxd), test(xxdNative), nil); err != nil {
+	encode := func(r io.Reader, w io.Writer) error {
+		return NewEncoder(w, Config{}).Encode(r)
+	}
+	if err := quick.CheckEqual(test(encode), test(xxdNative), nil); err != nil {
 		cErr := err.(*quick.CheckEqualError)
 		size := cErr.In[0].(uint64) % uint64(len(data))
 		for i := range cErr.Out1[0].([]string) {
@@ -68,7 +71,7 @@ func (p *pathologicalReader) Read(b []byte) (int, error) {
 	return n, nil
 }
 
-func BenchmarkXXD(b *testing.B) {
+func BenchmarkEncode(b *testing.B) {
 	b.StopTimer()
 	data := make([]byte, b.N)
 	if _, err := io.ReadFull(rand.Reader, data); err != nil {
@@ -76,15 +79,15 @@ func BenchmarkXXD(b *testing.B) {
 	}
 	buf := bytes.NewBuffer(data)
 	b.StartTimer()
-	if err := xxd(buf, ioutil.Discard, ""); err != nil {
+	if err := NewEncoder(io.Discard, Config{}).Encode(buf); err != nil {
 		b.Fatal(err)
 	}
 }
 
-func xxdNative(r io.Reader, w io.Writer, s string) error {
-	xxd := exec.Command("xxd.bak", "-")
-	xxd.Stdin = r
-	xxd.Stdout = w
-	xxd.Stderr = w
-	return xxd.Run()
+func xxdNative(r io.Reader, w io.Writer) error {
+	cmd := exec.Command("xxd.bak", "-")
+	cmd.Stdin = r
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
 }